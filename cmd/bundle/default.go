@@ -0,0 +1,162 @@
+package bundle
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/briandowns/spinner"
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+
+	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg/fileio"
+	"github.com/akshaybabloo/binstall/pkg/net"
+)
+
+var output string
+var token string
+var githubEnterpriseURL string
+var githubEnterpriseUploadURL string
+var targetOS string
+var targetArch string
+var excludeBinaries []string
+var includeBinaries []string
+
+// NewBundleCmd command function to download every configured binary into an offline bundle
+func NewBundleCmd() *cobra.Command {
+	var bundleCmd = &cobra.Command{
+		Use:   "bundle",
+		Short: "Download the configured binaries into an offline bundle",
+		Example: heredoc.Doc(`
+			To download every binary into a bundle
+			$ binstall bundle <config files folder> -o bundle.tar
+
+			To create a bundle for another platform
+			$ binstall bundle <config files folder> -o bundle.tar --os linux --arch arm64
+
+			To install the bundle on a machine without network access
+			$ binstall install --from-bundle bundle.tar`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("no config files folder provided")
+			}
+
+			stat, err := os.Stat(args[0])
+			if err != nil {
+				return err
+			}
+			if !stat.IsDir() {
+				return errors.New("provided path is not a directory")
+			}
+
+			data, err := fileio.ReadYamlFiles(filepath.FromSlash(args[0]))
+			if err != nil {
+				return err
+			}
+
+			if token == "" && os.Getenv("GITHUB_TOKEN") != "" {
+				token = os.Getenv("GITHUB_TOKEN")
+			}
+
+			manifest := models.BundleManifest{
+				Version:   models.BundleManifestVersion,
+				CreatedAt: time.Now().UTC(),
+				OS:        runtime.GOOS,
+				Arch:      runtime.GOARCH,
+			}
+			if targetOS != "" {
+				manifest.OS = targetOS
+			}
+			if targetArch != "" {
+				manifest.Arch = targetArch
+			}
+
+			s := spinner.New(spinner.CharSets[11], 100*time.Millisecond)
+			s.Suffix = color.GreenString(" Downloading binaries...")
+			s.Start()
+			defer s.Stop()
+
+			files := map[string]string{}
+			for binary, err := range data {
+				if err != nil {
+					return err
+				}
+
+				if binary.Ignore {
+					continue
+				}
+				if len(excludeBinaries) > 0 && slices.Contains(excludeBinaries, binary.Name) {
+					continue
+				}
+				if len(includeBinaries) > 0 && !slices.Contains(includeBinaries, binary.Name) {
+					continue
+				}
+
+				binary = net.WithGitHubEnterprise(binary, githubEnterpriseURL, githubEnterpriseUploadURL)
+				binary.Target = models.OSArch{OS: targetOS, Arch: targetArch}
+
+				s.Suffix = color.GreenString(fmt.Sprintf(" Downloading %s...", binary.Name))
+				resolved, err := net.ResolveBinary(binary, token)
+				if err != nil {
+					return fmt.Errorf("failed to resolve %s: %w", binary.Name, err)
+				}
+
+				dl, err := net.DownloadBinary(resolved)
+				if err != nil {
+					return err
+				}
+
+				entryPath := path.Join("files", dl.Name, dl.DownloadFileName)
+				files[entryPath] = dl.DownloadFilePath
+
+				// Download locations are recreated when the bundle is installed
+				entry := dl
+				entry.Token = ""
+				entry.DownloadFolder = ""
+				entry.DownloadFilePath = ""
+				manifest.Binaries = append(manifest.Binaries, models.BundleEntry{Binary: entry, Path: entryPath, SHA256: dl.Sha.Checksum})
+			}
+
+			if len(manifest.Binaries) == 0 {
+				return errors.New("no binaries to bundle")
+			}
+
+			if err := fileio.WriteBundle(output, manifest, files); err != nil {
+				return err
+			}
+
+			s.FinalMSG = color.GreenString(fmt.Sprintf("Bundle written to %s\n", output))
+			s.Stop()
+
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.AppendHeader(table.Row{"Name", "Version", "Asset", "SHA256"})
+			for _, entry := range manifest.Binaries {
+				t.AppendRow([]any{entry.Binary.Name, entry.Binary.NewVersion, entry.Binary.DownloadFileName, entry.SHA256})
+			}
+			t.SetStyle(table.StyleLight)
+			t.Render()
+
+			return nil
+		},
+	}
+
+	bundleCmd.Flags().StringVarP(&output, "output", "o", "bundle.tar", "Path of the bundle to write")
+	bundleCmd.Flags().StringVarP(&token, "token", "t", "", "GitHub token")
+	bundleCmd.Flags().StringVar(&githubEnterpriseURL, "github-enterprise-url", "", "GitHub Enterprise Server base URL used for binaries not hosted on github.com")
+	bundleCmd.Flags().StringVar(&githubEnterpriseUploadURL, "github-enterprise-upload-url", "", "GitHub Enterprise Server upload URL, defaults to the base URL")
+	bundleCmd.Flags().StringVar(&targetOS, "os", "", "Bundle binaries for this OS instead of the current one, e.g. linux")
+	bundleCmd.Flags().StringVar(&targetArch, "arch", "", "Bundle binaries for this arch instead of the current one, e.g. arm64 or armv7")
+	bundleCmd.Flags().StringSliceVarP(&excludeBinaries, "exclude", "e", []string{}, "Exclude binaries from the bundle")
+	bundleCmd.Flags().StringSliceVarP(&includeBinaries, "include", "i", []string{}, "Include only specified binaries in the bundle")
+
+	return bundleCmd
+}
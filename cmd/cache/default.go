@@ -0,0 +1,200 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+
+	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg/fileio"
+)
+
+var lockfilePath string
+
+// NewCacheCmd command function to inspect and clean the download cache
+func NewCacheCmd() *cobra.Command {
+	var cacheCmd = &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect and clean the download cache",
+		Example: heredoc.Doc(`
+			To list the cached assets
+			$ binstall cache ls
+
+			To remove the assets that are neither installed nor locked
+			$ binstall cache prune <config files folder>
+
+			To empty the cache
+			$ binstall cache clean`),
+	}
+
+	cacheCmd.AddCommand(newLsCmd())
+	cacheCmd.AddCommand(newCleanCmd())
+	cacheCmd.AddCommand(newPruneCmd())
+
+	return cacheCmd
+}
+
+func newLsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ls",
+		Short: "List the cached assets",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := fileio.CacheDir()
+			if err != nil {
+				return err
+			}
+			entries, err := fileio.ReadCache(dir)
+			if err != nil {
+				return err
+			}
+
+			if len(entries) == 0 {
+				fmt.Println(color.GreenString("The download cache is empty"))
+				return nil
+			}
+
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.AppendHeader(table.Row{"Name", "Version", "Asset", "Size", "Cached", "SHA256"})
+			var total int64
+			for _, entry := range entries {
+				total += entry.Size
+				t.AppendRow([]any{entry.Name, entry.Version, entry.Asset, formatSize(entry.Size), entry.CachedAt.Local().Format("2006-01-02 15:04"), entry.SHA256})
+			}
+			t.AppendFooter(table.Row{"", "", "", formatSize(total), "", dir})
+			t.SetStyle(table.StyleLight)
+			t.Render()
+			return nil
+		},
+	}
+}
+
+func newCleanCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clean",
+		Short: "Remove every cached asset",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := fileio.CacheDir()
+			if err != nil {
+				return err
+			}
+			entries, err := fileio.ReadCache(dir)
+			if err != nil {
+				return err
+			}
+			if err := os.RemoveAll(dir); err != nil {
+				return fmt.Errorf("failed to remove the download cache %s: %w", dir, err)
+			}
+
+			fmt.Println(color.GreenString(fmt.Sprintf("Removed %d cached assets from %s", len(entries), dir)))
+			return nil
+		},
+	}
+}
+
+func newPruneCmd() *cobra.Command {
+	pruneCmd := &cobra.Command{
+		Use:   "prune [config files folder]",
+		Short: "Remove the cached assets that are neither installed nor locked",
+		Long: heredoc.Doc(`
+			Remove the cached assets that are neither installed nor locked.
+
+			The assets of the installed binaries and of the versions they replaced
+			are kept, so they can be installed again offline. When a config files
+			folder is given, the assets in its lockfile are kept as well.`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keep := map[string]bool{}
+
+			statePath, err := fileio.StatePath()
+			if err != nil {
+				return err
+			}
+			state, err := fileio.ReadState(statePath)
+			if err != nil {
+				return err
+			}
+			for _, installed := range state.Binaries {
+				keep[installed.DownloadURL] = true
+				if installed.Previous != nil {
+					keep[installed.Previous.DownloadURL] = true
+				}
+			}
+
+			if len(args) > 0 && lockfilePath == "" {
+				stat, err := os.Stat(args[0])
+				if err != nil {
+					return err
+				}
+				if !stat.IsDir() {
+					return errors.New("provided path is not a directory")
+				}
+				lockfilePath, err = fileio.LockfilePath(filepath.FromSlash(args[0]))
+				if err != nil {
+					return err
+				}
+			}
+			if lockfilePath != "" {
+				lock, err := fileio.ReadLockfile(lockfilePath)
+				if err != nil {
+					return err
+				}
+				for _, entry := range lock.Binaries {
+					keep[entry.URL] = true
+				}
+			}
+
+			dir, err := fileio.CacheDir()
+			if err != nil {
+				return err
+			}
+			removed, err := fileio.PruneCache(dir, func(entry models.CacheEntry) bool {
+				return keep[entry.URL]
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(removed) == 0 {
+				fmt.Println(color.GreenString("Nothing to prune"))
+				return nil
+			}
+
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.AppendHeader(table.Row{"Name", "Removed Asset", "Size"})
+			for _, entry := range removed {
+				t.AppendRow([]any{entry.Name, entry.Asset, formatSize(entry.Size)})
+			}
+			t.SetStyle(table.StyleLight)
+			t.Render()
+			return nil
+		},
+	}
+
+	pruneCmd.Flags().StringVar(&lockfilePath, "lockfile", "", "Path of the lockfile whose assets are kept, defaults to binstall.lock next to the config files folder")
+
+	return pruneCmd
+}
+
+// formatSize formats a size in bytes with a binary unit, e.g. 12.3 MiB
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -3,6 +3,7 @@ package download
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"slices"
@@ -18,6 +19,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg"
 	"github.com/akshaybabloo/binstall/pkg/net"
 )
 
@@ -26,6 +28,13 @@ var nqa bool
 var dryRun bool
 var parallelCount int
 var token string
+var githubEnterpriseURL string
+var githubEnterpriseUploadURL string
+var locked bool
+var offline bool
+var lockfilePath string
+var targetOS string
+var targetArch string
 var excludeBinaries []string
 var includeBinaries []string
 
@@ -42,12 +51,22 @@ func NewDownloadCmd() *cobra.Command {
 			$ binstall download <config files folder> --check
 
 			To update without asking
-			$ binstall download <config files folder> --nqa`),
+			$ binstall download <config files folder> --nqa
+
+			To install exactly the versions recorded in binstall.lock
+			$ binstall download <config files folder> --locked
+
+			To download the binaries for another platform, e.g. for a container image
+			$ binstall download <config files folder> --os linux --arch arm64
+
+			To install the locked or installed versions from the download cache only
+			$ binstall download <config files folder> --offline`),
 		RunE: func(cmd *cobra.Command, args []string) error {
 
 			if len(args) == 0 {
 				return errors.New("no config files folder provided")
 			}
+			net.Offline = offline
 
 			s := spinner.New(spinner.CharSets[11], 100*time.Millisecond)
 			s.Suffix = color.GreenString(" Checking for updates...")
@@ -66,6 +85,26 @@ func NewDownloadCmd() *cobra.Command {
 				return err
 			}
 
+			if lockfilePath == "" {
+				lockfilePath, err = fileio.LockfilePath(args[0])
+				if err != nil {
+					return err
+				}
+			}
+			lock, err := fileio.ReadLockfile(lockfilePath)
+			if err != nil {
+				return err
+			}
+
+			statePath, err := fileio.StatePath()
+			if err != nil {
+				return err
+			}
+			state, err := fileio.ReadState(statePath)
+			if err != nil {
+				return err
+			}
+
 			var bins []models.Binaries
 			for binary, err := range data {
 				if err != nil {
@@ -88,7 +127,38 @@ func NewDownloadCmd() *cobra.Command {
 					token = os.Getenv("GITHUB_TOKEN")
 				}
 
-				updates, err := net.CheckUpdates(binary, token)
+				// Global enterprise endpoints apply to binaries that don't declare their own
+				binary = net.WithGitHubEnterprise(binary, githubEnterpriseURL, githubEnterpriseUploadURL)
+
+				binary.Target = models.OSArch{OS: targetOS, Arch: targetArch}
+
+				target := net.TargetFor(binary)
+
+				// Binaries that can't report their version are compared with the last installed one
+				checksVersion := slices.ContainsFunc(binary.Files, func(f models.File) bool { return f.CheckVersion })
+				if installed, ok := fileio.FindInstalledFor(state, binary.Name, target.OS, target.Arch); ok && !checksVersion {
+					binary.CurrentVersion = installed.Version
+				}
+
+				var updates models.Binaries
+				if locked || offline {
+					entry, ok := fileio.FindLockEntry(lock, binary.Name, target.OS, target.Arch)
+					if !ok && offline {
+						// Without a lockfile entry the installed version is installed again
+						if installed, found := fileio.FindInstalledFor(state, binary.Name, target.OS, target.Arch); found {
+							entry, ok = net.InstalledLockEntry(installed), true
+						}
+					}
+					if !ok && offline {
+						return fmt.Errorf("%s is neither locked nor installed for %s/%s, its version can't be resolved offline", binary.Name, target.OS, target.Arch)
+					}
+					if !ok {
+						return fmt.Errorf("%s is not locked for %s/%s in %s, run binstall lock first", binary.Name, target.OS, target.Arch, lockfilePath)
+					}
+					updates, err = net.CheckLockedUpdates(binary, entry)
+				} else {
+					updates, err = net.CheckUpdates(binary, token)
+				}
 				if err != nil {
 					return err
 				}
@@ -166,8 +236,10 @@ func NewDownloadCmd() *cobra.Command {
 
 			// Result type for download operations
 			type downloadResult struct {
-				name string
-				err  error
+				name      string
+				entry     models.LockEntry
+				installed models.InstalledBinary
+				err       error
 			}
 
 			resultCh := make(chan downloadResult, len(binUpdates))
@@ -189,8 +261,30 @@ func NewDownloadCmd() *cobra.Command {
 				go func() {
 					defer wg.Done()
 					for update := range workCh {
-						err := net.DownloadAndMoveFiles(update)
-						resultCh <- downloadResult{name: update.Name, err: err}
+						result := downloadResult{name: update.Name}
+						result.err = func() error {
+							dl, err := net.DownloadBinary(update)
+							if err != nil {
+								return err
+							}
+							result.entry = net.LockEntryFor(dl)
+
+							// The replaced files are kept so the update can be rolled back
+							dl.BackupDir, err = fileio.NewBackupDir(dl.Name)
+							if err != nil {
+								return err
+							}
+							installed, err := net.InstallDownloaded(dl)
+							if err != nil {
+								_ = os.RemoveAll(dl.BackupDir)
+								return err
+							}
+
+							result.installed, err = net.InstalledBinaryFor(installed)
+							result.installed.Backup = dl.BackupDir
+							return err
+						}()
+						resultCh <- result
 					}
 				}()
 			}
@@ -215,6 +309,29 @@ func NewDownloadCmd() *cobra.Command {
 				s.Suffix = color.GreenString(fmt.Sprintf(" Installing updates... (%d/%d)", completed, len(binUpdates)))
 				if result.err != nil {
 					errs = append(errs, fmt.Errorf("failed to update %s:\n%w", result.name, result.err))
+					continue
+				}
+				fileio.SetLockEntry(&lock, result.entry)
+				if old, ok := fileio.FindInstalledFor(state, result.installed.Name, result.installed.OS, result.installed.Arch); ok {
+					if err := net.RemoveStaleFiles(old, result.installed); err != nil {
+						errs = append(errs, err)
+					}
+				}
+				if err := fileio.ReplaceInstalled(&state, result.installed); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			if completed > len(errs) {
+				if err := fileio.WriteState(statePath, state); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			// Record what was installed, a locked or offline install leaves the lockfile as is
+			if !locked && !offline && completed > len(errs) {
+				if err := fileio.WriteLockfile(lockfilePath, lock); err != nil {
+					errs = append(errs, err)
 				}
 			}
 
@@ -223,7 +340,7 @@ func NewDownloadCmd() *cobra.Command {
 				s.Stop()
 				fmt.Println(color.RedString("Some updates failed to install:"))
 				for _, err := range errs {
-					fmt.Println(color.RedString(err.Error()))
+					fmt.Println(color.RedString(errorMessage(err)))
 				}
 				return nil
 			}
@@ -240,8 +357,36 @@ func NewDownloadCmd() *cobra.Command {
 	downloadCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be installed without making changes")
 	downloadCmd.Flags().IntVarP(&parallelCount, "parallel", "p", 4, "Number of parallel downloads")
 	downloadCmd.Flags().StringVarP(&token, "token", "t", "", "GitHub token")
+	downloadCmd.Flags().StringVar(&githubEnterpriseURL, "github-enterprise-url", "", "GitHub Enterprise Server base URL used for binaries not hosted on github.com")
+	downloadCmd.Flags().StringVar(&githubEnterpriseUploadURL, "github-enterprise-upload-url", "", "GitHub Enterprise Server upload URL, defaults to the base URL")
+	downloadCmd.Flags().BoolVar(&locked, "locked", false, "Install exactly the versions recorded in the lockfile")
+	downloadCmd.Flags().BoolVar(&offline, "offline", false, "Install the locked or installed versions from the download cache without network access")
+	downloadCmd.Flags().StringVar(&lockfilePath, "lockfile", "", "Path of the lockfile, defaults to binstall.lock next to the config files folder")
+	downloadCmd.Flags().StringVar(&targetOS, "os", "", "Install binaries for this OS instead of the current one, e.g. linux")
+	downloadCmd.Flags().StringVar(&targetArch, "arch", "", "Install binaries for this arch instead of the current one, e.g. arm64 or armv7")
 	downloadCmd.Flags().StringSliceVarP(&excludeBinaries, "exclude", "e", []string{}, "Exclude binaries from update")
 	downloadCmd.Flags().StringSliceVarP(&includeBinaries, "include", "i", []string{}, "Include only specified binaries in update")
 
 	return downloadCmd
 }
+
+// errorMessage returns the message of err, with a hint for download errors
+// caused by the server refusing the request
+func errorMessage(err error) string {
+	var statusErr *pkg.DownloadStatusError
+	if !errors.As(err, &statusErr) {
+		return err.Error()
+	}
+
+	msg := err.Error()
+	if statusErr.Body != "" {
+		msg += "\nResponse: " + statusErr.Body
+	}
+	switch statusErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests:
+		msg += "\nThe server refused the download or rate limited it, set GITHUB_TOKEN or use --token"
+	case http.StatusNotFound:
+		msg += "\nThe release asset wasn't found, check the download config of the binary"
+	}
+	return msg
+}
@@ -0,0 +1,118 @@
+package explain
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+
+	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg/fileio"
+	"github.com/akshaybabloo/binstall/pkg/net"
+)
+
+var token string
+var githubEnterpriseURL string
+var githubEnterpriseUploadURL string
+var targetOS string
+var targetArch string
+
+// NewExplainCmd command function to explain how the release asset of a binary is picked
+func NewExplainCmd() *cobra.Command {
+	var explainCmd = &cobra.Command{
+		Use:   "explain",
+		Short: "Explain which release asset is picked for a binary",
+		Example: heredoc.Doc(`
+			To list every release asset of a binary with its score
+			$ binstall explain <config files folder> <name>
+
+			To explain the asset picked for another platform
+			$ binstall explain <config files folder> <name> --os darwin --arch arm64`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return errors.New("a config files folder and a binary name are required")
+			}
+
+			binary, err := findBinary(args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			if token == "" && os.Getenv("GITHUB_TOKEN") != "" {
+				token = os.Getenv("GITHUB_TOKEN")
+			}
+			binary = net.WithGitHubEnterprise(binary, githubEnterpriseURL, githubEnterpriseUploadURL)
+
+			binary.Target = models.OSArch{OS: targetOS, Arch: targetArch}
+
+			picked, scores, err := net.ExplainAssets(binary, token)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("%s %s\n", binary.Name, picked.NewVersion)
+
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.AppendHeader(table.Row{"Asset", "OS/Arch", "Score", "Result", "Reason"})
+			for _, s := range scores {
+				result := ""
+				score := fmt.Sprint(s.Score)
+				switch {
+				case s.Picked:
+					result = color.GreenString("picked")
+				case s.Rejected:
+					result = color.RedString("rejected")
+					score = "-"
+				}
+				t.AppendRow([]any{s.Name, s.OSArch.OS + "/" + s.OSArch.Arch, score, result, strings.Join(s.Reasons, ", ")})
+			}
+			t.SetStyle(table.StyleLight)
+			t.Render()
+
+			if picked.DownloadURL == "" {
+				fmt.Println(color.RedString("No asset matches the target OS and Arch"))
+			}
+			return nil
+		},
+	}
+
+	explainCmd.Flags().StringVarP(&token, "token", "t", "", "GitHub token")
+	explainCmd.Flags().StringVar(&githubEnterpriseURL, "github-enterprise-url", "", "GitHub Enterprise Server base URL used for binaries not hosted on github.com")
+	explainCmd.Flags().StringVar(&githubEnterpriseUploadURL, "github-enterprise-upload-url", "", "GitHub Enterprise Server upload URL, defaults to the base URL")
+	explainCmd.Flags().StringVar(&targetOS, "os", "", "Explain the asset picked for this OS instead of the current one")
+	explainCmd.Flags().StringVar(&targetArch, "arch", "", "Explain the asset picked for this arch instead of the current one")
+
+	return explainCmd
+}
+
+// findBinary reads the config files in dir and returns the binary called name
+func findBinary(dir, name string) (models.Binaries, error) {
+	stat, err := os.Stat(dir)
+	if err != nil {
+		return models.Binaries{}, err
+	}
+	if !stat.IsDir() {
+		return models.Binaries{}, errors.New("provided path is not a directory")
+	}
+
+	data, err := fileio.ReadYamlFiles(filepath.FromSlash(dir))
+	if err != nil {
+		return models.Binaries{}, err
+	}
+	for binary, err := range data {
+		if err != nil {
+			return models.Binaries{}, err
+		}
+		if binary.Name == name {
+			return binary, nil
+		}
+	}
+	return models.Binaries{}, fmt.Errorf("no binary called %s found in %s", name, dir)
+}
@@ -0,0 +1,137 @@
+package install
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/briandowns/spinner"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg/fileio"
+	"github.com/akshaybabloo/binstall/pkg/net"
+)
+
+var fromBundle string
+var excludeBinaries []string
+var includeBinaries []string
+
+// NewInstallCmd command function to install binaries from an offline bundle
+func NewInstallCmd() *cobra.Command {
+	var installCmd = &cobra.Command{
+		Use:   "install",
+		Short: "Install binaries from an offline bundle",
+		Example: heredoc.Doc(`
+			To install every binary in a bundle without network access
+			$ binstall install --from-bundle bundle.tar`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if fromBundle == "" {
+				return errors.New("no bundle provided, use --from-bundle")
+			}
+
+			dir, err := os.MkdirTemp("", "binstall-bundle-")
+			if err != nil {
+				return fmt.Errorf("failed to create a temporary directory: %w", err)
+			}
+			defer os.RemoveAll(dir)
+
+			manifest, err := fileio.ReadBundle(fromBundle, dir)
+			if err != nil {
+				return err
+			}
+
+			statePath, err := fileio.StatePath()
+			if err != nil {
+				return err
+			}
+			state, err := fileio.ReadState(statePath)
+			if err != nil {
+				return err
+			}
+
+			s := spinner.New(spinner.CharSets[11], 100*time.Millisecond)
+			s.Suffix = color.GreenString(" Installing binaries...")
+			s.Start()
+
+			var errs []error
+			installed := 0
+			for _, entry := range manifest.Binaries {
+				b := entry.Binary
+				if len(excludeBinaries) > 0 && slices.Contains(excludeBinaries, b.Name) {
+					continue
+				}
+				if len(includeBinaries) > 0 && !slices.Contains(includeBinaries, b.Name) {
+					continue
+				}
+
+				s.Suffix = color.GreenString(fmt.Sprintf(" Installing %s...", b.Name))
+
+				// The asset is verified against the bundled checksum only, never a checksum URL
+				b.DownloadFilePath = filepath.Join(dir, filepath.FromSlash(entry.Path))
+				b.DownloadFolder = filepath.Dir(b.DownloadFilePath)
+				b.Sha = models.ShaInfo{ShaType: "sha256", Checksum: entry.SHA256}
+				b.Target = models.OSArch{OS: manifest.OS, Arch: manifest.Arch}
+
+				// The replaced files are kept so the install can be rolled back
+				b.BackupDir, err = fileio.NewBackupDir(b.Name)
+				if err != nil {
+					return err
+				}
+
+				done, err := net.InstallDownloaded(b)
+				if err != nil {
+					_ = os.RemoveAll(b.BackupDir)
+					errs = append(errs, fmt.Errorf("failed to install %s:\n%w", b.Name, err))
+					continue
+				}
+				installed++
+
+				entry, err := net.InstalledBinaryFor(done)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				entry.Backup = b.BackupDir
+				if old, ok := fileio.FindInstalledFor(state, entry.Name, entry.OS, entry.Arch); ok {
+					if err := net.RemoveStaleFiles(old, entry); err != nil {
+						errs = append(errs, err)
+					}
+				}
+				if err := fileio.ReplaceInstalled(&state, entry); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			if installed > 0 {
+				if err := fileio.WriteState(statePath, state); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			if len(errs) > 0 {
+				s.Stop()
+				fmt.Println(color.RedString("Some binaries failed to install:"))
+				for _, err := range errs {
+					fmt.Println(color.RedString(err.Error()))
+				}
+				return nil
+			}
+
+			s.FinalMSG = color.GreenString(fmt.Sprintf("%d binaries installed from %s\n", installed, fromBundle))
+			s.Stop()
+			return nil
+		},
+	}
+
+	installCmd.Flags().StringVar(&fromBundle, "from-bundle", "", "Bundle created with binstall bundle to install from")
+	installCmd.Flags().StringSliceVarP(&excludeBinaries, "exclude", "e", []string{}, "Exclude binaries from the install")
+	installCmd.Flags().StringSliceVarP(&includeBinaries, "include", "i", []string{}, "Install only the specified binaries")
+
+	return installCmd
+}
@@ -0,0 +1,72 @@
+package list
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+
+	"github.com/akshaybabloo/binstall/pkg/fileio"
+)
+
+var showFiles bool
+
+// NewListCmd command function to list the binaries installed by binstall
+func NewListCmd() *cobra.Command {
+	var listCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the binaries installed by binstall",
+		Example: heredoc.Doc(`
+			To list the installed binaries
+			$ binstall list
+
+			To list every installed file with its sha256
+			$ binstall list --files`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			statePath, err := fileio.StatePath()
+			if err != nil {
+				return err
+			}
+			state, err := fileio.ReadState(statePath)
+			if err != nil {
+				return err
+			}
+
+			if len(state.Binaries) == 0 {
+				fmt.Println(color.GreenString("No binaries installed"))
+				return nil
+			}
+
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			if showFiles {
+				t.AppendHeader(table.Row{"Name", "Version", "File", "SHA256"})
+				for _, b := range state.Binaries {
+					for _, f := range b.Files {
+						checksum := f.SHA256
+						if f.Symlink != "" {
+							checksum = "-> " + f.Symlink
+						}
+						t.AppendRow([]any{b.Name, b.Version, f.Path, checksum})
+					}
+				}
+			} else {
+				t.AppendHeader(table.Row{"Name", "Version", "Platform", "Installed", "Location", "Files", "Source"})
+				for _, b := range state.Binaries {
+					t.AppendRow([]any{b.Name, b.Version, b.OS + "/" + b.Arch, b.InstalledAt.Local().Format("2006-01-02 15:04"), b.InstallLocation, len(b.Files), b.Source})
+				}
+			}
+			t.SetStyle(table.StyleLight)
+			t.Render()
+
+			return nil
+		},
+	}
+
+	listCmd.Flags().BoolVar(&showFiles, "files", false, "List every installed file with its sha256")
+
+	return listCmd
+}
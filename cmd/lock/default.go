@@ -0,0 +1,157 @@
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/briandowns/spinner"
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+
+	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg/fileio"
+	"github.com/akshaybabloo/binstall/pkg/net"
+)
+
+var update bool
+var lockfilePath string
+var token string
+var githubEnterpriseURL string
+var githubEnterpriseUploadURL string
+var targetOS string
+var targetArch string
+
+// NewLockCmd command function to record the release assets of the configured binaries in the lockfile
+func NewLockCmd() *cobra.Command {
+	var lockCmd = &cobra.Command{
+		Use:   "lock",
+		Short: "Record the release assets of the binaries in binstall.lock",
+		Example: heredoc.Doc(`
+			To lock every binary that isn't locked yet
+			$ binstall lock <config files folder>
+
+			To refresh the lockfile with the latest releases
+			$ binstall lock <config files folder> --update
+
+			To refresh only some binaries
+			$ binstall lock <config files folder> --update <name> <name>
+
+			To lock the binaries for another platform as well
+			$ binstall lock <config files folder> --os darwin --arch arm64`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("no config files folder provided")
+			}
+			names := args[1:]
+
+			stat, err := os.Stat(args[0])
+			if err != nil {
+				return err
+			}
+			if !stat.IsDir() {
+				return errors.New("provided path is not a directory")
+			}
+
+			data, err := fileio.ReadYamlFiles(filepath.FromSlash(args[0]))
+			if err != nil {
+				return err
+			}
+
+			if lockfilePath == "" {
+				lockfilePath, err = fileio.LockfilePath(args[0])
+				if err != nil {
+					return err
+				}
+			}
+			lock, err := fileio.ReadLockfile(lockfilePath)
+			if err != nil {
+				return err
+			}
+
+			if token == "" && os.Getenv("GITHUB_TOKEN") != "" {
+				token = os.Getenv("GITHUB_TOKEN")
+			}
+
+			s := spinner.New(spinner.CharSets[11], 100*time.Millisecond)
+			s.Suffix = color.GreenString(" Locking binaries...")
+			s.Start()
+			defer s.Stop()
+
+			var changed []models.LockEntry
+			for binary, err := range data {
+				if err != nil {
+					return err
+				}
+				if binary.Ignore {
+					continue
+				}
+				if len(names) > 0 && !slices.Contains(names, binary.Name) {
+					continue
+				}
+
+				binary = net.WithGitHubEnterprise(binary, githubEnterpriseURL, githubEnterpriseUploadURL)
+				binary.Target = models.OSArch{OS: targetOS, Arch: targetArch}
+
+				target := net.TargetFor(binary)
+				if _, ok := fileio.FindLockEntry(lock, binary.Name, target.OS, target.Arch); ok && !update {
+					continue
+				}
+
+				s.Suffix = color.GreenString(fmt.Sprintf(" Locking %s...", binary.Name))
+				resolved, err := net.ResolveBinary(binary, token)
+				if err != nil {
+					return fmt.Errorf("failed to resolve %s: %w", binary.Name, err)
+				}
+
+				// The asset is downloaded once to record its checksum
+				dl, err := net.DownloadBinary(resolved)
+				if err != nil {
+					return err
+				}
+
+				entry := net.LockEntryFor(dl)
+				fileio.SetLockEntry(&lock, entry)
+				changed = append(changed, entry)
+			}
+
+			if len(changed) == 0 {
+				s.FinalMSG = color.GreenString("Lockfile is up to date\n")
+				return nil
+			}
+
+			if err := fileio.WriteLockfile(lockfilePath, lock); err != nil {
+				return err
+			}
+
+			s.FinalMSG = color.GreenString(fmt.Sprintf("Lockfile written to %s\n", lockfilePath))
+			s.Stop()
+
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.AppendHeader(table.Row{"Name", "Platform", "Tag", "Asset"})
+			for _, entry := range changed {
+				t.AppendRow([]any{entry.Name, entry.OS + "/" + entry.Arch, entry.Tag, entry.Asset})
+			}
+			t.SetStyle(table.StyleLight)
+			t.Render()
+
+			return nil
+		},
+	}
+
+	lockCmd.Flags().BoolVar(&update, "update", false, "Refresh the locked binaries with the latest releases")
+	lockCmd.Flags().StringVar(&lockfilePath, "lockfile", "", "Path of the lockfile, defaults to binstall.lock next to the config files folder")
+	lockCmd.Flags().StringVarP(&token, "token", "t", "", "GitHub token")
+	lockCmd.Flags().StringVar(&githubEnterpriseURL, "github-enterprise-url", "", "GitHub Enterprise Server base URL used for binaries not hosted on github.com")
+	lockCmd.Flags().StringVar(&githubEnterpriseUploadURL, "github-enterprise-upload-url", "", "GitHub Enterprise Server upload URL, defaults to the base URL")
+	lockCmd.Flags().StringVar(&targetOS, "os", "", "Lock binaries for this OS instead of the current one, e.g. linux")
+	lockCmd.Flags().StringVar(&targetArch, "arch", "", "Lock binaries for this arch instead of the current one, e.g. arm64 or armv7")
+
+	return lockCmd
+}
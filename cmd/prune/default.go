@@ -0,0 +1,80 @@
+package prune
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+
+	"github.com/akshaybabloo/binstall/pkg/fileio"
+	"github.com/akshaybabloo/binstall/pkg/net"
+)
+
+var keep int
+
+// NewPruneCmd command function to remove old versions of versioned binaries from the store
+func NewPruneCmd() *cobra.Command {
+	var pruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Remove old versions of versioned binaries from the store",
+		Example: heredoc.Doc(`
+			To keep only the two most recently installed versions of every binary
+			$ binstall prune --keep 2
+
+			To prune only some binaries
+			$ binstall prune --keep 1 <name> <name>`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if keep < 1 {
+				return errors.New("--keep must be at least 1, the active version is always kept")
+			}
+
+			statePath, err := fileio.StatePath()
+			if err != nil {
+				return err
+			}
+			state, err := fileio.ReadState(statePath)
+			if err != nil {
+				return err
+			}
+
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.AppendHeader(table.Row{"Name", "Removed Versions"})
+			pruned := 0
+			for _, installed := range state.Binaries {
+				if len(args) > 0 && !slices.Contains(args, installed.Name) {
+					continue
+				}
+
+				removed, err := net.PruneVersions(installed, keep)
+				if err != nil {
+					return fmt.Errorf("failed to prune %s: %w", installed.Name, err)
+				}
+				if len(removed) == 0 {
+					continue
+				}
+				pruned += len(removed)
+				t.AppendRow([]any{installed.Name, strings.Join(removed, ", ")})
+			}
+
+			if pruned == 0 {
+				fmt.Println(color.GreenString("Nothing to prune"))
+				return nil
+			}
+
+			t.SetStyle(table.StyleLight)
+			t.Render()
+			return nil
+		},
+	}
+
+	pruneCmd.Flags().IntVar(&keep, "keep", 2, "Number of versions to keep per binary, including the active one")
+
+	return pruneCmd
+}
@@ -0,0 +1,65 @@
+package rollback
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/akshaybabloo/binstall/pkg/fileio"
+	"github.com/akshaybabloo/binstall/pkg/net"
+)
+
+// NewRollbackCmd command function to go back to the version a binary had before its last install
+func NewRollbackCmd() *cobra.Command {
+	var rollbackCmd = &cobra.Command{
+		Use:   "rollback",
+		Short: "Restore the version a binary had before its last install",
+		Example: heredoc.Doc(`
+			To go back to the previous version of a binary
+			$ binstall rollback <name>`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("no binary name provided")
+			}
+
+			statePath, err := fileio.StatePath()
+			if err != nil {
+				return err
+			}
+			state, err := fileio.ReadState(statePath)
+			if err != nil {
+				return err
+			}
+
+			for _, name := range args {
+				installed, ok := fileio.FindInstalled(state, name)
+				if !ok {
+					return fmt.Errorf("%s is not installed by binstall, see binstall list", name)
+				}
+
+				previous, err := net.Rollback(installed)
+				if err != nil {
+					return fmt.Errorf("failed to roll back %s: %w", name, err)
+				}
+
+				fileio.SetInstalled(&state, previous)
+				if err := fileio.WriteState(statePath, state); err != nil {
+					return err
+				}
+
+				version := previous.Version
+				if version == "" {
+					version = "its previous version"
+				}
+				fmt.Println(color.GreenString(fmt.Sprintf("%s rolled back from %s to %s", name, installed.Version, version)))
+			}
+
+			return nil
+		},
+	}
+
+	return rollbackCmd
+}
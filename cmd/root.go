@@ -2,21 +2,36 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/spf13/cobra"
 
+	"github.com/akshaybabloo/binstall/cmd/bundle"
+	"github.com/akshaybabloo/binstall/cmd/cache"
 	"github.com/akshaybabloo/binstall/cmd/download"
+	"github.com/akshaybabloo/binstall/cmd/explain"
+	"github.com/akshaybabloo/binstall/cmd/install"
+	"github.com/akshaybabloo/binstall/cmd/list"
+	"github.com/akshaybabloo/binstall/cmd/lock"
+	"github.com/akshaybabloo/binstall/cmd/prune"
+	"github.com/akshaybabloo/binstall/cmd/rollback"
 	"github.com/akshaybabloo/binstall/cmd/schema"
+	"github.com/akshaybabloo/binstall/cmd/uninstall"
+	"github.com/akshaybabloo/binstall/cmd/use"
+	"github.com/akshaybabloo/binstall/pkg/net"
 )
 
 var verbose bool
+var retries int
+var retryWait time.Duration
 
 // NewRootCmd creates the root command for the binstall application,
 // sets up the version template, adds subcommands, and configures
-// persistent flags such as the verbose flag.
+// persistent flags such as the verbose and retry flags.
 func NewRootCmd(appVersion, buildDate string) *cobra.Command {
 	var rootCmd = &cobra.Command{
 		Use:   "binstall [OPTIONS] [COMMANDS]",
@@ -33,11 +48,31 @@ func NewRootCmd(appVersion, buildDate string) *cobra.Command {
 				}
 				logrus.SetLevel(level)
 			}
+
+			if retries < 0 {
+				return errors.New("--retries can't be negative")
+			}
+			if retryWait <= 0 {
+				return errors.New("--retry-wait must be positive")
+			}
+			net.Retry.Count = retries
+			net.Retry.Wait = retryWait
+			net.Retry.MaxWait = max(net.Retry.MaxWait, retryWait)
 			return nil
 		},
 	}
 
 	rootCmd.AddCommand(download.NewDownloadCmd())
+	rootCmd.AddCommand(bundle.NewBundleCmd())
+	rootCmd.AddCommand(install.NewInstallCmd())
+	rootCmd.AddCommand(lock.NewLockCmd())
+	rootCmd.AddCommand(list.NewListCmd())
+	rootCmd.AddCommand(uninstall.NewUninstallCmd())
+	rootCmd.AddCommand(rollback.NewRollbackCmd())
+	rootCmd.AddCommand(use.NewUseCmd())
+	rootCmd.AddCommand(prune.NewPruneCmd())
+	rootCmd.AddCommand(cache.NewCacheCmd())
+	rootCmd.AddCommand(explain.NewExplainCmd())
 	rootCmd.AddCommand(schema.NewSchemaCmd())
 
 	formattedVersion := format(appVersion, buildDate)
@@ -47,6 +82,8 @@ func NewRootCmd(appVersion, buildDate string) *cobra.Command {
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 
 	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "verbose output")
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", net.Retry.Count, "Number of times failed requests and interrupted downloads are retried")
+	rootCmd.PersistentFlags().DurationVar(&retryWait, "retry-wait", net.Retry.Wait, "Wait before the first retry, doubled for every following one")
 
 	return rootCmd
 }
@@ -0,0 +1,66 @@
+package uninstall
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/akshaybabloo/binstall/pkg/fileio"
+	"github.com/akshaybabloo/binstall/pkg/net"
+)
+
+var force bool
+
+// NewUninstallCmd command function to remove the files installed for a binary
+func NewUninstallCmd() *cobra.Command {
+	var uninstallCmd = &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove every file installed for a binary",
+		Example: heredoc.Doc(`
+			To remove a binary and every file it installed
+			$ binstall uninstall <name>
+
+			To remove the files even if they were changed after the install
+			$ binstall uninstall <name> --force`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("no binary name provided")
+			}
+
+			statePath, err := fileio.StatePath()
+			if err != nil {
+				return err
+			}
+			state, err := fileio.ReadState(statePath)
+			if err != nil {
+				return err
+			}
+
+			for _, name := range args {
+				installed, ok := fileio.FindInstalled(state, name)
+				if !ok {
+					return fmt.Errorf("%s is not installed by binstall, see binstall list", name)
+				}
+
+				if err := net.Uninstall(installed, force); err != nil {
+					return fmt.Errorf("failed to uninstall %s: %w", name, err)
+				}
+
+				fileio.RemoveInstalled(&state, name, installed.OS, installed.Arch)
+				if err := fileio.WriteState(statePath, state); err != nil {
+					return err
+				}
+				fmt.Println(color.GreenString(fmt.Sprintf("%s %s uninstalled, %d files removed", name, installed.Version, len(installed.Files))))
+			}
+
+			return nil
+		},
+	}
+
+	uninstallCmd.Flags().BoolVar(&force, "force", false, "Remove files even if they changed since they were installed")
+
+	return uninstallCmd
+}
@@ -0,0 +1,59 @@
+package use
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/akshaybabloo/binstall/pkg/fileio"
+	"github.com/akshaybabloo/binstall/pkg/net"
+)
+
+// NewUseCmd command function to switch a versioned binary to another installed version
+func NewUseCmd() *cobra.Command {
+	var useCmd = &cobra.Command{
+		Use:   "use",
+		Short: "Switch a versioned binary to another version in the store",
+		Example: heredoc.Doc(`
+			To switch a binary installed with versioned: true to another version
+			$ binstall use <name> <version>`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return errors.New("a binary name and a version are required")
+			}
+			name, v := args[0], args[1]
+
+			statePath, err := fileio.StatePath()
+			if err != nil {
+				return err
+			}
+			state, err := fileio.ReadState(statePath)
+			if err != nil {
+				return err
+			}
+
+			installed, ok := fileio.FindInstalled(state, name)
+			if !ok {
+				return fmt.Errorf("%s is not installed by binstall, see binstall list", name)
+			}
+
+			switched, err := net.UseVersion(installed, v)
+			if err != nil {
+				return fmt.Errorf("failed to switch %s to %s: %w", name, v, err)
+			}
+
+			fileio.SetInstalled(&state, switched)
+			if err := fileio.WriteState(statePath, state); err != nil {
+				return err
+			}
+
+			fmt.Println(color.GreenString(fmt.Sprintf("%s switched from %s to %s", name, installed.Version, v)))
+			return nil
+		},
+	}
+
+	return useCmd
+}
@@ -1,5 +1,10 @@
 package models
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 // VersionCommand holds the information about the version command that can be used to get the version of the binary
 type VersionCommand struct {
 	Args         string `yaml:"args,omitempty" json:"args,omitempty"`
@@ -20,7 +25,8 @@ type File struct {
 }
 
 // DownloadArchInfo holds the download file name for a specific OS/arch combination.
-// The FileName field supports Go text/template syntax, e.g. "bat-{{.Version}}-x86_64-unknown-linux-gnu.tar.gz"
+// The FileName field supports Go text/template syntax, e.g. "bat-{{.Version}}-x86_64-unknown-linux-gnu.tar.gz".
+// See utils.TemplateData for the available fields. "*" can be used as the OS or arch key.
 type DownloadArchInfo struct {
 	FileName string `yaml:"fileName,omitempty" json:"fileName,omitempty"`
 }
@@ -39,6 +45,27 @@ type ShaInfo struct {
 	Checksum string `yaml:"checksum,omitempty" json:"checksum,omitempty"`
 }
 
+// GenericSource describes where to find the latest version and download of a
+// binary that is not hosted on a forge. Used by the "others" provider.
+type GenericSource struct {
+	// VersionURL is the URL that returns the latest version as text, JSON or a response header
+	VersionURL string `yaml:"versionURL,omitempty" json:"versionURL,omitempty"`
+
+	// VersionRegex extracts the version from the body, JSON value or header.
+	// The first capture group is used if there is one, otherwise the whole match
+	VersionRegex string `yaml:"versionRegex,omitempty" json:"versionRegex,omitempty"`
+
+	// VersionJSONPath is a dot separated path to the version in a JSON body, e.g. "$.releases[0].version"
+	VersionJSONPath string `yaml:"versionJSONPath,omitempty" json:"versionJSONPath,omitempty"`
+
+	// VersionHeader reads the version from a response header instead of the body.
+	// Redirects are not followed, so "Location" can be used with VersionRegex
+	VersionHeader string `yaml:"versionHeader,omitempty" json:"versionHeader,omitempty"`
+
+	// DownloadURL is the download URL template, e.g. "https://dl.example.com/tool/{{.Version}}/tool.tar.gz"
+	DownloadURL string `yaml:"downloadURL,omitempty" json:"downloadURL,omitempty"`
+}
+
 // OSArch holds the information about the OS and Arch
 type OSArch struct {
 	// OS is the operating system
@@ -46,32 +73,184 @@ type OSArch struct {
 
 	// Arch is the architecture
 	Arch string `yaml:"arch,omitempty" json:"arch,omitempty"`
+
+	// Variant is the GOARM variant of 32-bit arm assets: 5, 6 or 7
+	Variant string `yaml:"variant,omitempty" json:"variant,omitempty"`
+
+	// Libc is the C library a Linux asset is built for: gnu, musl or static
+	Libc string `yaml:"libc,omitempty" json:"libc,omitempty"`
+}
+
+// AssetScore holds how a release asset was rated during asset selection
+type AssetScore struct {
+	// Name is the asset file name
+	Name string `yaml:"name" json:"name"`
+
+	// OSArch is the OS and arch detected from the name
+	OSArch OSArch `yaml:"osArch,omitempty" json:"osArch,omitempty"`
+
+	// Score is higher for better matches, it is 0 for rejected assets
+	Score int `yaml:"score" json:"score"`
+
+	// Rejected is set when the asset can't be installed on the target
+	Rejected bool `yaml:"rejected,omitempty" json:"rejected,omitempty"`
+
+	// Picked is set on the asset that is downloaded
+	Picked bool `yaml:"picked,omitempty" json:"picked,omitempty"`
+
+	// Reasons explains the score
+	Reasons []string `yaml:"reasons,omitempty" json:"reasons,omitempty"`
+}
+
+// Hooks holds the commands run at the lifecycle stages of a binary. They run
+// with BINSTALL_NAME, BINSTALL_VERSION, BINSTALL_INSTALL_DIR and
+// BINSTALL_DOWNLOAD_DIR set in their environment.
+type Hooks struct {
+	// PreInstall runs after the download is extracted, before the files are moved
+	PreInstall string `yaml:"preInstall,omitempty" json:"preInstall,omitempty"`
+
+	// PostInstall runs after the new files are installed and checked, e.g. to generate completions
+	PostInstall string `yaml:"postInstall,omitempty" json:"postInstall,omitempty"`
+
+	// PostUninstall runs after binstall uninstall removed the files
+	PostUninstall string `yaml:"postUninstall,omitempty" json:"postUninstall,omitempty"`
+
+	// Timeout is the time a hook may run, e.g. "30s". One minute when empty
+	Timeout string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// Completion is a shell completion script of a binary. It is copied from
+// the extracted archive, or printed by a generator command.
+type Completion struct {
+	// Shell is the shell of the script: bash, zsh or fish
+	Shell string `yaml:"shell" json:"shell"`
+
+	// Source is the path of the script in the archive, e.g. "completions/tool.bash"
+	Source string `yaml:"source,omitempty" json:"source,omitempty"`
+
+	// Command prints the script, e.g. "$BINSTALL_INSTALL_DIR/tool completion bash". Runs like a hook
+	Command string `yaml:"command,omitempty" json:"command,omitempty"`
+}
+
+// ManPage is a man page of a binary. It is copied from the extracted
+// archive, or printed by a generator command.
+type ManPage struct {
+	// Source is the path of the page in the archive, e.g. "man/tool.1"
+	Source string `yaml:"source,omitempty" json:"source,omitempty"`
+
+	// Command prints the page, e.g. "$BINSTALL_INSTALL_DIR/tool man". Runs like a hook
+	Command string `yaml:"command,omitempty" json:"command,omitempty"`
+
+	// Name is the file name of the page, e.g. "tool.1". The section is taken from it.
+	// Defaults to the file name of Source
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+}
+
+// Provider is the release provider of a binary: github, gitlab, gitea or
+// others. The numbers older configs used (1 github, 2 others) are still
+// accepted.
+type Provider string
+
+// legacyProviders maps the numeric providers of older configs to their names
+var legacyProviders = map[int]Provider{1: "github", 2: "others"}
+
+// UnmarshalYAML accepts the provider name or one of the legacy numbers
+func (p *Provider) UnmarshalYAML(unmarshal func(any) error) error {
+	var v any
+	if err := unmarshal(&v); err != nil {
+		return err
+	}
+	return p.set(v)
+}
+
+// UnmarshalJSON accepts the provider name or one of the legacy numbers
+func (p *Provider) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	return p.set(v)
+}
+
+func (p *Provider) set(v any) error {
+	var n int
+	switch v := v.(type) {
+	case nil:
+		*p = ""
+		return nil
+	case string:
+		*p = Provider(v)
+		return nil
+	case int:
+		n = v
+	case int64:
+		n = int(v)
+	case uint64:
+		n = int(v)
+	case float64:
+		n = int(v)
+	default:
+		return fmt.Errorf("unsupported provider %v, use github, gitlab, gitea or others", v)
+	}
+	provider, ok := legacyProviders[n]
+	if !ok {
+		return fmt.Errorf("unsupported provider %d, use github, gitlab, gitea or others", n)
+	}
+	*p = provider
+	return nil
 }
 
 // Binaries holds the information about the binaries
 type Binaries struct {
 	Name             string                                 `yaml:"name,omitempty" json:"name"`
 	URL              string                                 `yaml:"url,omitempty" json:"url"`
+	Version          string                                 `yaml:"version,omitempty" json:"version,omitempty"`       // Pinned tag or constraint, e.g. "v1.4.2", "~> 1.4" or ">=2.0,<3". Latest when empty
+	Channel          string                                 `yaml:"channel,omitempty" json:"channel,omitempty"`       // stable (default), prerelease or nightly
+	TagPrefix        string                                 `yaml:"tagPrefix,omitempty" json:"tagPrefix,omitempty"`   // Only use releases tagged with this prefix, e.g. "cli/". Stripped before version parsing
+	TagPattern       string                                 `yaml:"tagPattern,omitempty" json:"tagPattern,omitempty"` // Only use releases whose tag matches this regex. The first capture group is the version
 	Download         map[string]map[string]DownloadArchInfo `yaml:"download,omitempty" json:"download,omitempty"`
+	AssetPattern     string                                 `yaml:"assetPattern,omitempty" json:"assetPattern,omitempty"` // Glob, or regex wrapped in slashes, selecting the release asset. Supports templates
+	AssetExclude     []string                               `yaml:"assetExclude,omitempty" json:"assetExclude,omitempty"` // Globs or /regexes/ of release assets that are never picked
+	Libc             string                                 `yaml:"libc,omitempty" json:"libc,omitempty"`                 // Preferred C library on Linux, gnu or musl. Detected from the host when empty
 	Files            []File                                 `yaml:"files,omitempty" json:"files"`
 	Sha              ShaInfo                                `yaml:"sha,omitempty" json:"sha,omitempty"`
 	UpdatesAvailable bool                                   `yaml:"updatesAvailable,omitempty" json:"updatesAvailable,omitempty"`
 	Description      string                                 `yaml:"description,omitempty" json:"description,omitempty"`
-	Provider         int                                    `yaml:"provider,omitempty" json:"provider,omitempty"`
+	Provider         Provider                               `yaml:"provider,omitempty" json:"provider,omitempty" jsonschema:"enum=github,enum=gitlab,enum=gitea,enum=forgejo,enum=codeberg,enum=others"` // github, gitlab, gitea or others. Detected from the URL when empty
+	GitHubEnterprise GitHubEnterprise                       `yaml:"githubEnterprise,omitempty" json:"githubEnterprise,omitempty"`
+	Generic          GenericSource                          `yaml:"generic,omitempty" json:"generic,omitempty"`
 	OsInfo           OSArch                                 `yaml:"osInfo,omitempty" json:"osInfo,omitempty"`
 	DownloadURL      string                                 `yaml:"downloadURL,omitempty" json:"downloadURL,omitempty"`
 	DownloadFileName string                                 `yaml:"downloadFileName,omitempty" json:"downloadFileName,omitempty"`
 	ContentType      string                                 `yaml:"contentType,omitempty" json:"contentType,omitempty"`
+	DownloadSize     int64                                  `yaml:"downloadSize,omitempty" json:"downloadSize,omitempty"` // Size of the asset reported by the release, 0 when unknown
 	DownloadFolder   string                                 `yaml:"downloadFolder,omitempty" json:"downloadFolder,omitempty"`
 	DownloadFilePath string                                 `yaml:"downloadPath,omitempty" json:"downloadPath,omitempty"`
 	InstallLocation  string                                 `yaml:"installLocation" json:"installLocation"`
+	Versioned        bool                                   `yaml:"versioned,omitempty" json:"versioned,omitempty"` // Install to the version store and only symlink the files into installLocation
 	CurrentVersion   string                                 `yaml:"currentVersion,omitempty" json:"currentVersion,omitempty"`
 	NewVersion       string                                 `yaml:"newVersion,omitempty" json:"newVersion,omitempty"`
 
 	// Ignore if the binary should be ignored
 	Ignore bool `yaml:"ignore,omitempty" json:"ignore,omitempty"`
-	// Shell is the shell command to run the binary, if any
+	// Shell runs the hooks, e.g. "bash" or "pwsh". sh (cmd on Windows) when empty
 	Shell string `yaml:"shell,omitempty" json:"shell,omitempty"`
+	// Hooks are commands run around the install and uninstall of the binary
+	Hooks Hooks `yaml:"hooks,omitempty" json:"hooks,omitempty"`
+	// Completions are installed to the bash, zsh and fish completion directories under $XDG_DATA_HOME
+	Completions []Completion `yaml:"completions,omitempty" json:"completions,omitempty"`
+	// ManPages are installed to $XDG_DATA_HOME/man
+	ManPages []ManPage `yaml:"manpages,omitempty" json:"manpages,omitempty"`
 	// Token is the token to be used for the download authentication
 	Token string `yaml:"_" json:"_"`
+	// Target overrides the OS and arch the binary is installed for, the host when empty
+	Target OSArch `yaml:"-" json:"-"`
+	// InstalledFiles are the paths written to the install location by the last install
+	InstalledFiles []string `yaml:"-" json:"-"`
+	// InstalledDirs are the directories created in the install location by the last install
+	InstalledDirs []string `yaml:"-" json:"-"`
+	// BackupDir is where the files replaced by an install are backed up, a temporary directory when empty
+	BackupDir string `yaml:"-" json:"-"`
+	// StoreDir is the version store directory a versioned binary was installed to
+	StoreDir string `yaml:"-" json:"-"`
 }
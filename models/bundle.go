@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// BundleManifestVersion is the version of the bundle manifest format written by binstall
+const BundleManifestVersion = 1
+
+// BundleManifest describes the binaries stored in an offline bundle
+type BundleManifest struct {
+	// Version is the manifest format version
+	Version int `yaml:"version" json:"version"`
+
+	// CreatedAt is when the bundle was created
+	CreatedAt time.Time `yaml:"createdAt" json:"createdAt"`
+
+	// OS and Arch are the platform the binaries were resolved for
+	OS   string `yaml:"os" json:"os"`
+	Arch string `yaml:"arch" json:"arch"`
+
+	// Binaries are the resolved binaries in the bundle
+	Binaries []BundleEntry `yaml:"binaries" json:"binaries"`
+}
+
+// BundleEntry is a resolved binary and its downloaded asset in a bundle
+type BundleEntry struct {
+	// Binary is the resolved binary configuration
+	Binary Binaries `yaml:"binary" json:"binary"`
+
+	// Path is the path of the downloaded asset inside the bundle
+	Path string `yaml:"path" json:"path"`
+
+	// SHA256 is the checksum of the downloaded asset
+	SHA256 string `yaml:"sha256" json:"sha256"`
+}
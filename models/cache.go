@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// CacheEntry is a release asset in the download cache
+type CacheEntry struct {
+	URL      string    `yaml:"url" json:"url"`         // Download URL of the asset
+	Name     string    `yaml:"name" json:"name"`       // Binary the asset was downloaded for
+	Version  string    `yaml:"version" json:"version"` // Release tag the asset was downloaded for
+	Asset    string    `yaml:"asset" json:"asset"`     // File name of the asset
+	SHA256   string    `yaml:"sha256" json:"sha256"`   // Checksum of the asset, the key of its file in the cache
+	Size     int64     `yaml:"size" json:"size"`
+	CachedAt time.Time `yaml:"cachedAt" json:"cachedAt"`
+}
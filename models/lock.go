@@ -0,0 +1,25 @@
+package models
+
+// LockfileVersion is the version of the lockfile format written by binstall
+const LockfileVersion = 1
+
+// Lockfile records the exact release asset installed for every binary, so
+// the same files are installed on every machine
+type Lockfile struct {
+	// Version is the lockfile format version
+	Version int `yaml:"version" json:"version"`
+
+	// Binaries are the locked binaries, one entry per binary and platform
+	Binaries []LockEntry `yaml:"binaries" json:"binaries"`
+}
+
+// LockEntry is the locked release asset of a binary on one platform
+type LockEntry struct {
+	Name   string `yaml:"name" json:"name"`
+	OS     string `yaml:"os" json:"os"`
+	Arch   string `yaml:"arch" json:"arch"`
+	Tag    string `yaml:"tag" json:"tag"`       // Resolved release tag
+	URL    string `yaml:"url" json:"url"`       // Download URL of the asset
+	Asset  string `yaml:"asset" json:"asset"`   // File name of the asset
+	SHA256 string `yaml:"sha256" json:"sha256"` // Checksum of the asset
+}
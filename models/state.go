@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// StateVersion is the version of the install state format written by binstall
+const StateVersion = 1
+
+// State records every binary installed by binstall on this machine
+type State struct {
+	// Version is the state format version
+	Version int `yaml:"version" json:"version"`
+
+	// Binaries are the installed binaries, one entry per binary and platform
+	Binaries []InstalledBinary `yaml:"binaries" json:"binaries"`
+}
+
+// InstalledBinary is a binary installed by binstall
+type InstalledBinary struct {
+	Name            string           `yaml:"name" json:"name"`
+	Version         string           `yaml:"version" json:"version"`                   // Installed version, without the tag prefix
+	Tag             string           `yaml:"tag,omitempty" json:"tag,omitempty"`       // Release tag the version was installed from
+	Source          string           `yaml:"source" json:"source"`                     // URL of the binary in the config
+	DownloadURL     string           `yaml:"downloadURL" json:"downloadURL"`           // URL of the installed asset
+	Asset           string           `yaml:"asset,omitempty" json:"asset,omitempty"`   // File name of the installed asset
+	SHA256          string           `yaml:"sha256,omitempty" json:"sha256,omitempty"` // Checksum of the installed asset
+	OS              string           `yaml:"os" json:"os"`
+	Arch            string           `yaml:"arch" json:"arch"`
+	InstallLocation string           `yaml:"installLocation" json:"installLocation"`
+	InstalledAt     time.Time        `yaml:"installedAt" json:"installedAt"`
+	Files           []InstalledFile  `yaml:"files" json:"files"`
+	Dirs            []string         `yaml:"dirs,omitempty" json:"dirs,omitempty"`         // Directories created by the install, parents first
+	Store           string           `yaml:"store,omitempty" json:"store,omitempty"`       // Version store directory of a versioned install, the files are symlinks into it
+	Backup          string           `yaml:"backup,omitempty" json:"backup,omitempty"`     // Directory holding the files this install replaced
+	Previous        *InstalledBinary `yaml:"previous,omitempty" json:"previous,omitempty"` // Version this install replaced, restored by rollback
+	Shell           string           `yaml:"shell,omitempty" json:"shell,omitempty"`       // Shell of the hooks
+	Hooks           Hooks            `yaml:"hooks,omitempty" json:"hooks,omitempty"`       // Hooks of the binary, postUninstall runs on uninstall
+}
+
+// InstalledFile is a file written to the install location
+type InstalledFile struct {
+	Path    string `yaml:"path" json:"path"`
+	SHA256  string `yaml:"sha256,omitempty" json:"sha256,omitempty"`   // Checksum of the file, empty for symlinks
+	Symlink string `yaml:"symlink,omitempty" json:"symlink,omitempty"` // Target of the symlink, if the file is one
+}
@@ -9,3 +9,33 @@ type GitHubInfo struct {
 	// Repo is the repository name related to it's Owner
 	Repo string `yaml:"repo,omitempty" json:"repo,omitempty"`
 }
+
+// GitHubEnterprise holds the API endpoints of a GitHub Enterprise Server instance
+type GitHubEnterprise struct {
+	// BaseURL is the API base URL, e.g. https://github.example.com/. "api/v3/" is appended when missing
+	BaseURL string `yaml:"baseURL,omitempty" json:"baseURL,omitempty"`
+
+	// UploadURL is the upload API URL, defaults to BaseURL. "api/uploads/" is appended when missing
+	UploadURL string `yaml:"uploadURL,omitempty" json:"uploadURL,omitempty"`
+}
+
+// GitLabInfo holds the information about the GitLab URL
+type GitLabInfo struct {
+	// BaseURL is the scheme and host of the GitLab instance, e.g. https://gitlab.com
+	BaseURL string `yaml:"baseURL,omitempty" json:"baseURL,omitempty"`
+
+	// Project is the full project path including any subgroups
+	Project string `yaml:"project,omitempty" json:"project,omitempty"`
+}
+
+// GiteaInfo holds the information about a Gitea, Forgejo or Codeberg URL
+type GiteaInfo struct {
+	// BaseURL is the scheme and host of the instance, e.g. https://codeberg.org
+	BaseURL string `yaml:"baseURL,omitempty" json:"baseURL,omitempty"`
+
+	// Owner is the owner of the repository
+	Owner string `yaml:"owner,omitempty" json:"owner,omitempty"`
+
+	// Repo is the repository name related to it's Owner
+	Repo string `yaml:"repo,omitempty" json:"repo,omitempty"`
+}
@@ -1,5 +1,33 @@
 package pkg
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var ErrNetBinaryNotFound = errors.New("no binary found for the current OS and Arch")
+var ErrNetAmbiguousAsset = errors.New("more than one release asset matches the asset pattern")
+var ErrNetFileModified = errors.New("installed file was modified since it was installed")
+
+var ErrDownloadHTTPStatus = errors.New("download failed with an unexpected HTTP status")
+var ErrDownloadSizeMismatch = errors.New("downloaded file size does not match the release asset")
+var ErrDownloadUnexpectedContent = errors.New("download returned a web page instead of the release asset")
+var ErrDownloadNotCached = errors.New("release asset is not in the download cache")
+
+// DownloadStatusError is returned when a download responds with a status
+// other than 2xx. It matches ErrDownloadHTTPStatus with errors.Is.
+type DownloadStatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+	// Body is the start of the response body, e.g. the rate limit message
+	Body string
+}
+
+func (e *DownloadStatusError) Error() string {
+	return fmt.Sprintf("%s: %s returned %s", ErrDownloadHTTPStatus, e.URL, e.Status)
+}
+
+func (e *DownloadStatusError) Unwrap() error {
+	return ErrDownloadHTTPStatus
+}
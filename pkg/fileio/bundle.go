@@ -0,0 +1,156 @@
+package fileio
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/akshaybabloo/binstall/models"
+)
+
+// BundleManifestName is the name of the manifest inside a bundle
+const BundleManifestName = "manifest.json"
+
+// WriteBundle writes manifest and the downloaded assets to a tar archive at p.
+// files maps the Path of every manifest entry to the local file to store.
+func WriteBundle(p string, manifest models.BundleManifest, files map[string]string) (err error) {
+	out, err := os.Create(p)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle %s: %w", p, err)
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("failed to close bundle %s: %w", p, cerr)
+		}
+		if err != nil {
+			_ = os.Remove(p)
+		}
+	}()
+
+	tw := tar.NewWriter(out)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode bundle manifest: %w", err)
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: BundleManifestName, Mode: 0o644, Size: int64(len(data)), ModTime: manifest.CreatedAt}); err != nil {
+		return fmt.Errorf("failed to write bundle manifest: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write bundle manifest: %w", err)
+	}
+
+	for _, entry := range manifest.Binaries {
+		src, ok := files[entry.Path]
+		if !ok {
+			return fmt.Errorf("no file provided for %s in the bundle", entry.Path)
+		}
+		if err := addBundleFile(tw, entry.Path, src); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish bundle %s: %w", p, err)
+	}
+	return nil
+}
+
+// addBundleFile stores the local file src in tw under name
+func addBundleFile(tw *tar.Writer, name, src string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", src, err)
+	}
+
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return fmt.Errorf("failed to create tar header for %s: %w", src, err)
+	}
+	hdr.Name = filepath.ToSlash(name)
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to add %s to the bundle: %w", name, err)
+	}
+	if _, err := io.Copy(tw, f); err != nil {
+		return fmt.Errorf("failed to add %s to the bundle: %w", name, err)
+	}
+	return nil
+}
+
+// ReadBundle extracts the bundle at p into dir and returns its manifest.
+// Entries that would be written outside of dir are rejected.
+func ReadBundle(p, dir string) (models.BundleManifest, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return models.BundleManifest{}, fmt.Errorf("failed to open bundle %s: %w", p, err)
+	}
+	defer f.Close()
+
+	var manifest models.BundleManifest
+	found := false
+
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return models.BundleManifest{}, fmt.Errorf("failed to read bundle %s: %w", p, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := filepath.FromSlash(hdr.Name)
+		if !filepath.IsLocal(name) {
+			return models.BundleManifest{}, fmt.Errorf("invalid path %q in bundle %s", hdr.Name, p)
+		}
+
+		if hdr.Name == BundleManifestName {
+			if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+				return models.BundleManifest{}, fmt.Errorf("failed to parse the manifest of bundle %s: %w", p, err)
+			}
+			found = true
+			continue
+		}
+
+		if err := extractBundleFile(tr, filepath.Join(dir, name)); err != nil {
+			return models.BundleManifest{}, err
+		}
+	}
+
+	if !found {
+		return models.BundleManifest{}, fmt.Errorf("bundle %s has no %s", p, BundleManifestName)
+	}
+	if manifest.Version != models.BundleManifestVersion {
+		return models.BundleManifest{}, fmt.Errorf("unsupported bundle manifest version %d in %s", manifest.Version, p)
+	}
+	return manifest, nil
+}
+
+// extractBundleFile writes the current entry of tr to dst
+func extractBundleFile(tr *tar.Reader, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", dst, err)
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+	if _, err := io.Copy(out, tr); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to extract %s: %w", dst, err)
+	}
+	return out.Close()
+}
@@ -0,0 +1,88 @@
+package fileio
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akshaybabloo/binstall/models"
+)
+
+func TestWriteAndReadBundle(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "tool.tar.gz")
+	require.NoError(t, os.WriteFile(src, []byte("archive"), 0o644))
+
+	manifest := models.BundleManifest{
+		Version:   models.BundleManifestVersion,
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		OS:        "linux",
+		Arch:      "arm64",
+		Binaries: []models.BundleEntry{{
+			Binary: models.Binaries{Name: "tool", NewVersion: "v1.2.3", DownloadFileName: "tool.tar.gz"},
+			Path:   "files/tool/tool.tar.gz",
+			SHA256: "abc",
+		}},
+	}
+
+	bundle := filepath.Join(t.TempDir(), "bundle.tar")
+	require.NoError(t, WriteBundle(bundle, manifest, map[string]string{"files/tool/tool.tar.gz": src}))
+
+	dir := t.TempDir()
+	got, err := ReadBundle(bundle, dir)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, got)
+
+	data, err := os.ReadFile(filepath.Join(dir, "files", "tool", "tool.tar.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, "archive", string(data))
+
+	t.Run("missing_file_removes_bundle", func(t *testing.T) {
+		p := filepath.Join(t.TempDir(), "bundle.tar")
+		require.Error(t, WriteBundle(p, manifest, map[string]string{}))
+		assert.NoFileExists(t, p)
+	})
+}
+
+func TestReadBundle_Invalid(t *testing.T) {
+	writeTar := func(t *testing.T, entries map[string]string) string {
+		t.Helper()
+		p := filepath.Join(t.TempDir(), "bundle.tar")
+		f, err := os.Create(p)
+		require.NoError(t, err)
+		tw := tar.NewWriter(f)
+		for name, content := range entries {
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+			_, err := tw.Write([]byte(content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, tw.Close())
+		require.NoError(t, f.Close())
+		return p
+	}
+
+	t.Run("path_traversal", func(t *testing.T) {
+		p := writeTar(t, map[string]string{"../evil": "x"})
+		_, err := ReadBundle(p, t.TempDir())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid path")
+	})
+
+	t.Run("missing_manifest", func(t *testing.T) {
+		p := writeTar(t, map[string]string{"files/tool/tool": "x"})
+		_, err := ReadBundle(p, t.TempDir())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "manifest.json")
+	})
+
+	t.Run("unsupported_version", func(t *testing.T) {
+		p := writeTar(t, map[string]string{"manifest.json": `{"version": 99}`})
+		_, err := ReadBundle(p, t.TempDir())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported bundle manifest version")
+	})
+}
@@ -0,0 +1,209 @@
+package fileio
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/akshaybabloo/binstall/models"
+)
+
+// The cache keeps every asset once under its sha256 and an entry per
+// download URL pointing at it, so machines sharing a cache directory can
+// look assets up by either
+const (
+	cacheAssetsDir  = "sha256"
+	cacheEntriesDir = "urls"
+)
+
+// CacheDir returns the directory downloaded assets are cached in,
+// $XDG_CACHE_HOME/binstall or ~/.cache/binstall
+func CacheDir() (string, error) {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, "binstall"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory for the cache directory: %w", err)
+	}
+	return filepath.Join(home, ".cache", "binstall"), nil
+}
+
+// CachedAssetPath returns the path of the cached asset with the sha256 sum
+// in the cache dir. The file may not exist.
+func CachedAssetPath(dir, sum string) string {
+	return filepath.Join(dir, cacheAssetsDir, strings.ToLower(sum))
+}
+
+// cacheEntryPath returns the path of the cache entry of the download url
+func cacheEntryPath(dir, url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(dir, cacheEntriesDir, hex.EncodeToString(sum[:])+".json")
+}
+
+// FindCached returns the cache entry of the download url. Entries whose
+// asset is missing from the cache are not returned.
+func FindCached(dir, url string) (models.CacheEntry, bool, error) {
+	entry, err := readCacheEntry(cacheEntryPath(dir, url))
+	if errors.Is(err, os.ErrNotExist) {
+		return models.CacheEntry{}, false, nil
+	}
+	if err != nil {
+		return models.CacheEntry{}, false, err
+	}
+	if entry.URL != url {
+		return models.CacheEntry{}, false, nil
+	}
+	if _, err := os.Stat(CachedAssetPath(dir, entry.SHA256)); err != nil {
+		return models.CacheEntry{}, false, nil
+	}
+	return entry, true, nil
+}
+
+// AddToCache copies the asset at p into the cache dir and records entry for
+// its download URL. entry.SHA256 must be the checksum of the file at p.
+func AddToCache(dir string, entry models.CacheEntry, p string) error {
+	asset := CachedAssetPath(dir, entry.SHA256)
+	if _, err := os.Stat(asset); errors.Is(err, os.ErrNotExist) {
+		f, err := os.Open(p)
+		if err != nil {
+			return fmt.Errorf("failed to cache %s: %w", p, err)
+		}
+		err = writeCacheFile(asset, f)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to cache %s: %w", p, err)
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	return writeCacheFile(cacheEntryPath(dir, entry.URL), bytes.NewReader(data))
+}
+
+// ReadCache returns the entries of the cache dir sorted by name and URL.
+// A missing cache directory is returned as an empty cache.
+func ReadCache(dir string) ([]models.CacheEntry, error) {
+	files, err := os.ReadDir(filepath.Join(dir, cacheEntriesDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading cache %s: %w", dir, err)
+	}
+
+	var entries []models.CacheEntry
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+		entry, err := readCacheEntry(filepath.Join(dir, cacheEntriesDir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(a, b models.CacheEntry) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.URL, b.URL))
+	})
+	return entries, nil
+}
+
+// PruneCache removes the entries of the cache dir keep returns false for,
+// and the assets no entry points at anymore. The removed entries are
+// returned.
+func PruneCache(dir string, keep func(models.CacheEntry) bool) ([]models.CacheEntry, error) {
+	entries, err := ReadCache(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []models.CacheEntry
+	used := map[string]bool{}
+	for _, entry := range entries {
+		if keep(entry) {
+			used[strings.ToLower(entry.SHA256)] = true
+			continue
+		}
+		if err := os.Remove(cacheEntryPath(dir, entry.URL)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, fmt.Errorf("failed to remove cache entry of %s: %w", entry.URL, err)
+		}
+		removed = append(removed, entry)
+	}
+
+	assets, err := os.ReadDir(filepath.Join(dir, cacheAssetsDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return removed, nil
+	}
+	if err != nil {
+		return removed, fmt.Errorf("error reading cache %s: %w", dir, err)
+	}
+	for _, asset := range assets {
+		if used[asset.Name()] {
+			continue
+		}
+		if strings.HasPrefix(asset.Name(), ".") {
+			// An asset another process is still writing
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dir, cacheAssetsDir, asset.Name())); err != nil {
+			return removed, fmt.Errorf("failed to remove cached asset %s: %w", asset.Name(), err)
+		}
+	}
+	return removed, nil
+}
+
+// readCacheEntry reads the cache entry at p
+func readCacheEntry(p string) (models.CacheEntry, error) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return models.CacheEntry{}, err
+	}
+	var entry models.CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return models.CacheEntry{}, fmt.Errorf("error parsing cache entry %s: %w", p, err)
+	}
+	return entry, nil
+}
+
+// writeCacheFile writes r to p through a temporary file, so other processes
+// sharing the cache never see a partial file
+func writeCacheFile(p string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p), "."+filepath.Base(p)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache file %s: %w", p, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write cache file %s: %w", p, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache file %s: %w", p, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write cache file %s: %w", p, err)
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return fmt.Errorf("failed to write cache file %s: %w", p, err)
+	}
+	return nil
+}
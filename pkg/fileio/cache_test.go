@@ -0,0 +1,100 @@
+package fileio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg/utils"
+)
+
+// cacheAsset writes content to a file and returns its cache entry for url
+func cacheAsset(t *testing.T, dir, name, url, content string) models.CacheEntry {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), filepath.Base(url))
+	require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	sum, err := utils.CalculateSHA256(p)
+	require.NoError(t, err)
+
+	entry := models.CacheEntry{URL: url, Name: name, Asset: filepath.Base(url), SHA256: sum, Size: int64(len(content))}
+	require.NoError(t, AddToCache(dir, entry, p))
+	return entry
+}
+
+func TestCacheDir(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", root)
+	got, err := CacheDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "binstall"), got)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", "relative")
+	got, err = CacheDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".cache", "binstall"), got)
+}
+
+func TestAddToCache(t *testing.T) {
+	dir := t.TempDir()
+	entry := cacheAsset(t, dir, "tool", "https://example.test/v1/tool.tar.gz", "v1")
+
+	got, ok, err := FindCached(dir, entry.URL)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, entry.SHA256, got.SHA256)
+	assert.Equal(t, "tool.tar.gz", got.Asset)
+
+	data, err := os.ReadFile(CachedAssetPath(dir, entry.SHA256))
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(data))
+
+	_, ok, err = FindCached(dir, "https://example.test/v2/tool.tar.gz")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, os.Remove(CachedAssetPath(dir, entry.SHA256)))
+	_, ok, err = FindCached(dir, entry.URL)
+	require.NoError(t, err)
+	assert.False(t, ok, "entries without their asset are misses")
+}
+
+func TestReadCache(t *testing.T) {
+	dir := t.TempDir()
+	entries, err := ReadCache(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "a missing cache is empty")
+
+	cacheAsset(t, dir, "zeta", "https://example.test/zeta.tar.gz", "z")
+	cacheAsset(t, dir, "alpha", "https://example.test/alpha.tar.gz", "a")
+	entries, err = ReadCache(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "alpha", entries[0].Name)
+	assert.Equal(t, "zeta", entries[1].Name)
+}
+
+func TestPruneCache(t *testing.T) {
+	dir := t.TempDir()
+	kept := cacheAsset(t, dir, "tool", "https://example.test/v2/tool.tar.gz", "v2")
+	old := cacheAsset(t, dir, "tool", "https://example.test/v1/tool.tar.gz", "v1")
+	// The same asset from a mirror shares its file with the kept entry
+	mirror := cacheAsset(t, dir, "tool", "https://mirror.test/v2/tool.tar.gz", "v2")
+
+	removed, err := PruneCache(dir, func(e models.CacheEntry) bool { return e.URL == kept.URL })
+	require.NoError(t, err)
+	require.Len(t, removed, 2)
+	assert.ElementsMatch(t, []string{old.URL, mirror.URL}, []string{removed[0].URL, removed[1].URL})
+
+	entries, err := ReadCache(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, kept.URL, entries[0].URL)
+	assert.FileExists(t, CachedAssetPath(dir, kept.SHA256))
+	assert.NoFileExists(t, CachedAssetPath(dir, old.SHA256))
+}
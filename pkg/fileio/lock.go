@@ -0,0 +1,105 @@
+package fileio
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/akshaybabloo/binstall/models"
+)
+
+// LockfileName is the name of the lockfile written next to the config directory
+const LockfileName = "binstall.lock"
+
+// LockfilePath returns the path of the lockfile for the config directory dir
+func LockfilePath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	return filepath.Join(filepath.Dir(abs), LockfileName), nil
+}
+
+// ReadLockfile reads the lockfile at p. A missing lockfile is returned as an
+// empty one.
+func ReadLockfile(p string) (models.Lockfile, error) {
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return models.Lockfile{Version: models.LockfileVersion}, nil
+	}
+	if err != nil {
+		return models.Lockfile{}, fmt.Errorf("error reading lockfile %s: %w", p, err)
+	}
+
+	var lock models.Lockfile
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return models.Lockfile{}, fmt.Errorf("error parsing lockfile %s: %w", p, err)
+	}
+	if lock.Version != models.LockfileVersion {
+		return models.Lockfile{}, fmt.Errorf("unsupported lockfile version %d in %s", lock.Version, p)
+	}
+	return lock, nil
+}
+
+// WriteLockfile writes lock to p with the entries sorted by name and
+// platform, so the file diffs cleanly. The file is replaced atomically.
+func WriteLockfile(p string, lock models.Lockfile) error {
+	lock.Version = models.LockfileVersion
+	slices.SortFunc(lock.Binaries, func(a, b models.LockEntry) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.OS, b.OS), cmp.Compare(a.Arch, b.Arch))
+	})
+
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return fmt.Errorf("failed to encode lockfile: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p), LockfileName+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write lockfile %s: %w", p, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write lockfile %s: %w", p, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write lockfile %s: %w", p, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write lockfile %s: %w", p, err)
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return fmt.Errorf("failed to write lockfile %s: %w", p, err)
+	}
+	return nil
+}
+
+// FindLockEntry returns the entry of lock for the binary name on goos/goarch
+func FindLockEntry(lock models.Lockfile, name, goos, goarch string) (models.LockEntry, bool) {
+	i := slices.IndexFunc(lock.Binaries, func(e models.LockEntry) bool {
+		return e.Name == name && e.OS == goos && e.Arch == goarch
+	})
+	if i == -1 {
+		return models.LockEntry{}, false
+	}
+	return lock.Binaries[i], true
+}
+
+// SetLockEntry adds entry to lock, replacing the entry of the same binary and platform
+func SetLockEntry(lock *models.Lockfile, entry models.LockEntry) {
+	i := slices.IndexFunc(lock.Binaries, func(e models.LockEntry) bool {
+		return e.Name == entry.Name && e.OS == entry.OS && e.Arch == entry.Arch
+	})
+	if i == -1 {
+		lock.Binaries = append(lock.Binaries, entry)
+		return
+	}
+	lock.Binaries[i] = entry
+}
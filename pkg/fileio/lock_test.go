@@ -0,0 +1,72 @@
+package fileio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akshaybabloo/binstall/models"
+)
+
+func TestLockfilePath(t *testing.T) {
+	root := t.TempDir()
+	got, err := LockfilePath(filepath.Join(root, "configs"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, LockfileName), got)
+}
+
+func TestReadWriteLockfile(t *testing.T) {
+	t.Run("missing_lockfile_is_empty", func(t *testing.T) {
+		lock, err := ReadLockfile(filepath.Join(t.TempDir(), LockfileName))
+		require.NoError(t, err)
+		assert.Equal(t, models.LockfileVersion, lock.Version)
+		assert.Empty(t, lock.Binaries)
+	})
+
+	t.Run("round_trip_is_sorted", func(t *testing.T) {
+		p := filepath.Join(t.TempDir(), LockfileName)
+		lock := models.Lockfile{Binaries: []models.LockEntry{
+			{Name: "zeta", OS: "linux", Arch: "amd64", Tag: "v2.0.0", URL: "https://example.test/zeta", Asset: "zeta.tar.gz", SHA256: "bb"},
+			{Name: "alpha", OS: "linux", Arch: "arm64", Tag: "v1.0.0", URL: "https://example.test/alpha-arm64", Asset: "alpha-arm64", SHA256: "ab"},
+			{Name: "alpha", OS: "darwin", Arch: "arm64", Tag: "v1.0.0", URL: "https://example.test/alpha-darwin", Asset: "alpha-darwin", SHA256: "aa"},
+		}}
+		require.NoError(t, WriteLockfile(p, lock))
+
+		got, err := ReadLockfile(p)
+		require.NoError(t, err)
+		require.Len(t, got.Binaries, 3)
+		assert.Equal(t, "darwin", got.Binaries[0].OS)
+		assert.Equal(t, "arm64", got.Binaries[1].Arch)
+		assert.Equal(t, "zeta", got.Binaries[2].Name)
+		assert.Equal(t, "bb", got.Binaries[2].SHA256)
+
+		entries, err := os.ReadDir(filepath.Dir(p))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "no temporary files are left behind")
+	})
+
+	t.Run("unsupported_version", func(t *testing.T) {
+		p := filepath.Join(t.TempDir(), LockfileName)
+		require.NoError(t, os.WriteFile(p, []byte("version: 9\n"), 0o644))
+		_, err := ReadLockfile(p)
+		require.Error(t, err)
+	})
+}
+
+func TestFindAndSetLockEntry(t *testing.T) {
+	var lock models.Lockfile
+	SetLockEntry(&lock, models.LockEntry{Name: "tool", OS: "linux", Arch: "amd64", Tag: "v1.0.0"})
+	SetLockEntry(&lock, models.LockEntry{Name: "tool", OS: "darwin", Arch: "arm64", Tag: "v1.0.0"})
+	SetLockEntry(&lock, models.LockEntry{Name: "tool", OS: "linux", Arch: "amd64", Tag: "v1.1.0"})
+	require.Len(t, lock.Binaries, 2)
+
+	got, ok := FindLockEntry(lock, "tool", "linux", "amd64")
+	require.True(t, ok)
+	assert.Equal(t, "v1.1.0", got.Tag)
+
+	_, ok = FindLockEntry(lock, "tool", "windows", "amd64")
+	assert.False(t, ok)
+}
@@ -0,0 +1,212 @@
+package fileio
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+
+	"github.com/akshaybabloo/binstall/models"
+)
+
+// StateFileName is the name of the install state file
+const StateFileName = "state.json"
+
+// StateDir returns the directory binstall keeps its install state in,
+// $XDG_STATE_HOME/binstall or ~/.local/state/binstall
+func StateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, "binstall"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory for the state directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", "binstall"), nil
+}
+
+// DataHome returns the user data directory, $XDG_DATA_HOME or ~/.local/share
+func DataHome() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" && filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory for the data directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+// StoreDir returns the directory versioned binaries are installed to,
+// $XDG_DATA_HOME/binstall or ~/.local/share/binstall
+func StoreDir() (string, error) {
+	dir, err := DataHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "binstall"), nil
+}
+
+// NewBackupDir creates a directory in the state directory to back up the
+// files an install of the binary name replaces
+func NewBackupDir(name string) (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "backups")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	backup, err := os.MkdirTemp(dir, name+"-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	return backup, nil
+}
+
+// StatePath returns the path of the install state file
+func StatePath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, StateFileName), nil
+}
+
+// ReadState reads the install state at p. A missing state file is returned
+// as an empty state.
+func ReadState(p string) (models.State, error) {
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return models.State{Version: models.StateVersion}, nil
+	}
+	if err != nil {
+		return models.State{}, fmt.Errorf("error reading state %s: %w", p, err)
+	}
+
+	var state models.State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return models.State{}, fmt.Errorf("error parsing state %s: %w", p, err)
+	}
+	if state.Version != models.StateVersion {
+		return models.State{}, fmt.Errorf("unsupported state version %d in %s", state.Version, p)
+	}
+	return state, nil
+}
+
+// WriteState writes state to p with the binaries sorted by name and
+// platform. The directory of p is created if needed and the file is
+// replaced atomically.
+func WriteState(p string, state models.State) error {
+	state.Version = models.StateVersion
+	slices.SortFunc(state.Binaries, func(a, b models.InstalledBinary) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.OS, b.OS), cmp.Compare(a.Arch, b.Arch))
+	})
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p), StateFileName+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write state %s: %w", p, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write state %s: %w", p, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state %s: %w", p, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write state %s: %w", p, err)
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return fmt.Errorf("failed to write state %s: %w", p, err)
+	}
+	return nil
+}
+
+// FindInstalled returns the state entry of the binary name installed for
+// this machine
+func FindInstalled(state models.State, name string) (models.InstalledBinary, bool) {
+	return FindInstalledFor(state, name, runtime.GOOS, runtime.GOARCH)
+}
+
+// FindInstalledFor returns the state entry of the binary name installed for
+// goos/goarch, e.g. with --os or --arch
+func FindInstalledFor(state models.State, name, goos, goarch string) (models.InstalledBinary, bool) {
+	i := slices.IndexFunc(state.Binaries, func(e models.InstalledBinary) bool {
+		return e.Name == name && e.OS == goos && e.Arch == goarch
+	})
+	if i == -1 {
+		return models.InstalledBinary{}, false
+	}
+	return state.Binaries[i], true
+}
+
+// SetInstalled adds entry to state, replacing the entry of the same binary and platform
+func SetInstalled(state *models.State, entry models.InstalledBinary) {
+	i := slices.IndexFunc(state.Binaries, func(e models.InstalledBinary) bool {
+		return e.Name == entry.Name && e.OS == entry.OS && e.Arch == entry.Arch
+	})
+	if i == -1 {
+		state.Binaries = append(state.Binaries, entry)
+		return
+	}
+	state.Binaries[i] = entry
+}
+
+// RemoveInstalled removes the entry of the binary name on goos/goarch from state
+func RemoveInstalled(state *models.State, name, goos, goarch string) {
+	state.Binaries = slices.DeleteFunc(state.Binaries, func(e models.InstalledBinary) bool {
+		return e.Name == name && e.OS == goos && e.Arch == goarch
+	})
+}
+
+// ReplaceInstalled records a new install of a binary. The entry it replaces
+// becomes entry.Previous so it can be rolled back to, and the backup of the
+// replaced entry is removed, as only one previous version is kept. An empty
+// backup of a binary that wasn't installed before is removed too.
+func ReplaceInstalled(state *models.State, entry models.InstalledBinary) error {
+	old, ok := FindInstalledFor(*state, entry.Name, entry.OS, entry.Arch)
+	if ok {
+		if old.Backup != "" {
+			if err := os.RemoveAll(old.Backup); err != nil {
+				return fmt.Errorf("failed to remove backup %s: %w", old.Backup, err)
+			}
+		}
+		old.Backup = ""
+		old.Previous = nil
+		entry.Previous = &old
+	} else if entry.Backup != "" {
+		files, err := os.ReadDir(entry.Backup)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to read backup %s: %w", entry.Backup, err)
+		}
+		if len(files) == 0 {
+			if err := os.RemoveAll(entry.Backup); err != nil {
+				return fmt.Errorf("failed to remove backup %s: %w", entry.Backup, err)
+			}
+			entry.Backup = ""
+		} else {
+			// Files were replaced that binstall didn't install, their version is unknown
+			entry.Previous = &models.InstalledBinary{Name: entry.Name, OS: entry.OS, Arch: entry.Arch, InstallLocation: entry.InstallLocation}
+		}
+	}
+
+	SetInstalled(state, entry)
+	return nil
+}
@@ -0,0 +1,189 @@
+package fileio
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akshaybabloo/binstall/models"
+)
+
+func TestStatePath(t *testing.T) {
+	t.Run("xdg_state_home", func(t *testing.T) {
+		root := t.TempDir()
+		t.Setenv("XDG_STATE_HOME", root)
+		got, err := StatePath()
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(root, "binstall", StateFileName), got)
+	})
+
+	t.Run("relative_xdg_state_home_is_ignored", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		t.Setenv("XDG_STATE_HOME", "relative")
+		got, err := StatePath()
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(home, ".local", "state", "binstall", StateFileName), got)
+	})
+}
+
+func TestStoreDir(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", root)
+	got, err := StoreDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "binstall"), got)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "")
+	got, err = StoreDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".local", "share", "binstall"), got)
+}
+
+func TestReadWriteState(t *testing.T) {
+	t.Run("missing_state_is_empty", func(t *testing.T) {
+		state, err := ReadState(filepath.Join(t.TempDir(), StateFileName))
+		require.NoError(t, err)
+		assert.Equal(t, models.StateVersion, state.Version)
+		assert.Empty(t, state.Binaries)
+	})
+
+	t.Run("round_trip_creates_directory", func(t *testing.T) {
+		p := filepath.Join(t.TempDir(), "binstall", StateFileName)
+		installedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		state := models.State{Binaries: []models.InstalledBinary{
+			{Name: "zeta", Version: "2.0.0", InstalledAt: installedAt, Files: []models.InstalledFile{{Path: "/opt/bin/zeta", SHA256: "bb"}}},
+			{Name: "alpha", Version: "1.0.0", InstalledAt: installedAt, Files: []models.InstalledFile{{Path: "/opt/bin/a", Symlink: "alpha"}}},
+		}}
+		require.NoError(t, WriteState(p, state))
+
+		got, err := ReadState(p)
+		require.NoError(t, err)
+		require.Len(t, got.Binaries, 2)
+		assert.Equal(t, "alpha", got.Binaries[0].Name)
+		assert.Equal(t, "alpha", got.Binaries[0].Files[0].Symlink)
+		assert.Equal(t, "bb", got.Binaries[1].Files[0].SHA256)
+		assert.True(t, installedAt.Equal(got.Binaries[1].InstalledAt))
+
+		entries, err := os.ReadDir(filepath.Dir(p))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "no temporary files are left behind")
+	})
+
+	t.Run("unsupported_version", func(t *testing.T) {
+		p := filepath.Join(t.TempDir(), StateFileName)
+		require.NoError(t, os.WriteFile(p, []byte(`{"version": 9}`), 0o644))
+		_, err := ReadState(p)
+		require.Error(t, err)
+	})
+}
+
+func TestFindAndSetInstalled(t *testing.T) {
+	var state models.State
+	SetInstalled(&state, models.InstalledBinary{Name: "tool", Version: "1.0.0", OS: runtime.GOOS, Arch: runtime.GOARCH})
+	SetInstalled(&state, models.InstalledBinary{Name: "other", Version: "3.0.0", OS: runtime.GOOS, Arch: runtime.GOARCH})
+	SetInstalled(&state, models.InstalledBinary{Name: "tool", Version: "1.1.0", OS: runtime.GOOS, Arch: runtime.GOARCH})
+	require.Len(t, state.Binaries, 2)
+
+	got, ok := FindInstalled(state, "tool")
+	require.True(t, ok)
+	assert.Equal(t, "1.1.0", got.Version)
+
+	_, ok = FindInstalled(state, "missing")
+	assert.False(t, ok)
+
+	t.Run("platforms_are_kept_apart", func(t *testing.T) {
+		state := models.State{Binaries: slices.Clone(state.Binaries)}
+		SetInstalled(&state, models.InstalledBinary{Name: "tool", Version: "2.0.0", OS: "plan9", Arch: "arm64"})
+		require.Len(t, state.Binaries, 3, "an install for another platform doesn't replace the host install")
+
+		got, ok := FindInstalled(state, "tool")
+		require.True(t, ok)
+		assert.Equal(t, "1.1.0", got.Version)
+		got, ok = FindInstalledFor(state, "tool", "plan9", "arm64")
+		require.True(t, ok)
+		assert.Equal(t, "2.0.0", got.Version)
+
+		RemoveInstalled(&state, "tool", "plan9", "arm64")
+		_, ok = FindInstalledFor(state, "tool", "plan9", "arm64")
+		assert.False(t, ok)
+		_, ok = FindInstalled(state, "tool")
+		assert.True(t, ok)
+	})
+
+	RemoveInstalled(&state, "tool", runtime.GOOS, runtime.GOARCH)
+	_, ok = FindInstalled(state, "tool")
+	assert.False(t, ok)
+	assert.Len(t, state.Binaries, 1)
+}
+
+func TestReplaceInstalled(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	t.Run("previous_entry_is_kept_once", func(t *testing.T) {
+		var state models.State
+		first, err := NewBackupDir("tool")
+		require.NoError(t, err)
+		require.NoError(t, ReplaceInstalled(&state, models.InstalledBinary{Name: "tool", Version: "1.0.0", OS: runtime.GOOS, Arch: runtime.GOARCH, Backup: first}))
+
+		second, err := NewBackupDir("tool")
+		require.NoError(t, err)
+		require.NoError(t, ReplaceInstalled(&state, models.InstalledBinary{Name: "tool", Version: "2.0.0", OS: runtime.GOOS, Arch: runtime.GOARCH, Backup: second}))
+
+		got, ok := FindInstalled(state, "tool")
+		require.True(t, ok)
+		require.NotNil(t, got.Previous)
+		assert.Equal(t, "1.0.0", got.Previous.Version)
+		assert.Empty(t, got.Previous.Backup)
+		assert.Equal(t, second, got.Backup)
+		assert.NoDirExists(t, first, "the backup of the replaced entry is removed")
+		assert.DirExists(t, second)
+	})
+
+	t.Run("empty_backup_of_new_binary_is_removed", func(t *testing.T) {
+		var state models.State
+		backup, err := NewBackupDir("fresh")
+		require.NoError(t, err)
+		require.NoError(t, ReplaceInstalled(&state, models.InstalledBinary{Name: "fresh", Version: "1.0.0", OS: runtime.GOOS, Arch: runtime.GOARCH, Backup: backup}))
+
+		got, _ := FindInstalled(state, "fresh")
+		assert.Nil(t, got.Previous)
+		assert.Empty(t, got.Backup)
+		assert.NoDirExists(t, backup)
+	})
+
+	t.Run("replaced_files_of_unknown_version", func(t *testing.T) {
+		var state models.State
+		backup, err := NewBackupDir("manual")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(backup, "manual"), []byte("old"), 0o755))
+		require.NoError(t, ReplaceInstalled(&state, models.InstalledBinary{Name: "manual", Version: "1.0.0", OS: runtime.GOOS, Arch: runtime.GOARCH, Backup: backup}))
+
+		got, _ := FindInstalled(state, "manual")
+		require.NotNil(t, got.Previous)
+		assert.Empty(t, got.Previous.Version)
+		assert.Equal(t, runtime.GOOS, got.Previous.OS, "a rollback replaces the same platform entry")
+		assert.Equal(t, backup, got.Backup)
+	})
+
+	t.Run("install_for_another_platform", func(t *testing.T) {
+		var state models.State
+		require.NoError(t, ReplaceInstalled(&state, models.InstalledBinary{Name: "tool", Version: "1.0.0", OS: runtime.GOOS, Arch: runtime.GOARCH}))
+		require.NoError(t, ReplaceInstalled(&state, models.InstalledBinary{Name: "tool", Version: "2.0.0", OS: "plan9", Arch: "arm64"}))
+
+		host, ok := FindInstalled(state, "tool")
+		require.True(t, ok)
+		assert.Equal(t, "1.0.0", host.Version)
+		cross, ok := FindInstalledFor(state, "tool", "plan9", "arm64")
+		require.True(t, ok)
+		assert.Nil(t, cross.Previous, "the host install isn't the previous version of a cross install")
+	})
+}
@@ -0,0 +1,218 @@
+package net
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/akshaybabloo/binstall/models"
+)
+
+// externalBackupDir holds the backups of files outside of the install
+// location, e.g. shell completions, by their absolute path
+const externalBackupDir = ".external"
+
+// backupPath returns where the file at dst is backed up in b.BackupDir
+func backupPath(b *models.Binaries, dst string) string {
+	if rel, err := filepath.Rel(b.InstallLocation, dst); err == nil && filepath.IsLocal(rel) {
+		return filepath.Join(b.BackupDir, rel)
+	}
+	abs := strings.TrimPrefix(dst[len(filepath.VolumeName(dst)):], string(filepath.Separator))
+	return filepath.Join(b.BackupDir, externalBackupDir, abs)
+}
+
+// backupFile copies the existing file at dst to b.BackupDir before an
+// install replaces it. The copy keeps its path relative to the install
+// location. Files written by the current install and files that are already
+// backed up are left alone.
+func backupFile(b *models.Binaries, dst string) error {
+	if b.BackupDir == "" || slices.Contains(b.InstalledFiles, dst) {
+		return nil
+	}
+
+	info, err := os.Lstat(dst)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat %s for backup: %w", dst, err)
+	}
+	if info.IsDir() {
+		return nil
+	}
+
+	backup := backupPath(b, dst)
+	if _, err := os.Lstat(backup); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(backup), 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory for %s: %w", dst, err)
+	}
+
+	logrus.Debugf("Backing up %s to %s", dst, backup)
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(dst)
+		if err != nil {
+			return fmt.Errorf("failed to read symlink %s: %w", dst, err)
+		}
+		if err := os.Symlink(target, backup); err != nil {
+			return fmt.Errorf("failed to back up symlink %s: %w", dst, err)
+		}
+		return nil
+	}
+	return copyFileWithMode(dst, backup, info.Mode())
+}
+
+// restoreFiles undoes an install: the installed files are removed, the files
+// saved in backupDir are moved back to installLocation and the directories
+// the install created are removed if they are empty. The restored paths are
+// returned.
+func restoreFiles(installLocation, backupDir string, files, dirs []string) ([]string, error) {
+	for _, f := range files {
+		if err := os.Remove(f); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove %s: %w", f, err)
+		}
+	}
+
+	var restored []string
+	err := filepath.Walk(backupDir, func(backup string, info os.FileInfo, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(backupDir, backup)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(installLocation, rel)
+		if abs, ok := strings.CutPrefix(rel, externalBackupDir+string(filepath.Separator)); ok {
+			dst = string(filepath.Separator) + abs
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", dst, err)
+		}
+		if err := os.Remove(dst); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", dst, err)
+		}
+
+		logrus.Debugf("Restoring %s", dst)
+		if err := moveFile(backup, dst); err != nil {
+			return err
+		}
+		restored = append(restored, dst)
+		return nil
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to restore files from %s: %w", backupDir, err)
+	}
+
+	if err := removeEmptyDirs(dirs); err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// RemoveStaleFiles removes the files of the old install of a binary that the
+// new install no longer has, e.g. a file dropped from a wildcard install, so
+// they don't stay behind untracked. They are backed up in installed.Backup
+// first, so a rollback restores them.
+func RemoveStaleFiles(old, installed models.InstalledBinary) error {
+	var current []string
+	for _, f := range installed.Files {
+		current = append(current, f.Path)
+	}
+
+	b := models.Binaries{InstallLocation: installed.InstallLocation, BackupDir: installed.Backup}
+	for _, f := range old.Files {
+		if slices.Contains(current, f.Path) {
+			continue
+		}
+		if err := backupFile(&b, f.Path); err != nil {
+			return err
+		}
+		logrus.Debugf("Removing %s, it is not part of the new install", f.Path)
+		if err := os.Remove(f.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", f.Path, err)
+		}
+	}
+
+	var dirs []string
+	for _, dir := range old.Dirs {
+		if !slices.Contains(installed.Dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return removeEmptyDirs(dirs)
+}
+
+// moveFile moves src to dst, copying it when they are on different
+// filesystems. Symlinks are recreated.
+func moveFile(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", src, err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return replaceFile(src, dst, info.Mode())
+	}
+
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	target, err := os.Readlink(src)
+	if err != nil {
+		return fmt.Errorf("failed to read symlink %s: %w", src, err)
+	}
+	if err := os.Symlink(target, dst); err != nil {
+		return fmt.Errorf("failed to create symlink %s -> %s: %w", dst, target, err)
+	}
+	return os.Remove(src)
+}
+
+// Rollback restores the files an install replaced and returns the state
+// entry of the version that is installed again. The files of the current
+// install are removed first.
+func Rollback(installed models.InstalledBinary) (models.InstalledBinary, error) {
+	if installed.Previous == nil || installed.Backup == "" {
+		return models.InstalledBinary{}, fmt.Errorf("no previous version of %s to roll back to", installed.Name)
+	}
+
+	var files []string
+	for _, f := range installed.Files {
+		files = append(files, f.Path)
+	}
+	restored, err := restoreFiles(installed.InstallLocation, installed.Backup, files, installed.Dirs)
+	if err != nil {
+		return models.InstalledBinary{}, err
+	}
+
+	previous := *installed.Previous
+	previous.InstalledAt = time.Now().UTC()
+	// Files of the previous version the current install didn't touch are still
+	// in place, the restored ones are hashed again
+	restoredFiles, err := installedFiles(restored)
+	if err != nil {
+		return models.InstalledBinary{}, err
+	}
+	previous.Files = nil
+	for _, f := range installed.Previous.Files {
+		if !slices.Contains(files, f.Path) && !slices.Contains(restored, f.Path) {
+			previous.Files = append(previous.Files, f)
+		}
+	}
+	previous.Files = append(previous.Files, restoredFiles...)
+
+	if err := os.RemoveAll(installed.Backup); err != nil {
+		return models.InstalledBinary{}, fmt.Errorf("failed to remove backup %s: %w", installed.Backup, err)
+	}
+	return previous, nil
+}
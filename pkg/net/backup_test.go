@@ -0,0 +1,196 @@
+package net
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akshaybabloo/binstall/models"
+)
+
+func TestInstallDownloaded_RestoresOnFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("end-to-end install relies on POSIX shell scripts")
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "release.tar.gz")
+	makeTarGz(t, archivePath, map[string]string{
+		"tool":   "#!/bin/sh\necho \"9.9.9\"",
+		"helper": "new helper",
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, archivePath)
+	}))
+	t.Cleanup(srv.Close)
+
+	installDir := t.TempDir()
+	t.Setenv("PATH", installDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	oldTool := "#!/bin/sh\necho \"1.0.0\""
+	require.NoError(t, os.WriteFile(filepath.Join(installDir, "tool"), []byte(oldTool), 0o755))
+
+	b := models.Binaries{
+		Name:             uniqueTempName(t),
+		NewVersion:       "2.0.0",
+		DownloadURL:      srv.URL + "/release.tar.gz",
+		DownloadFileName: "release.tar.gz",
+		ContentType:      "application/gzip",
+		InstallLocation:  installDir,
+		Files: []models.File{
+			{
+				FileName:       "tool",
+				CopyIt:         true,
+				CheckVersion:   true,
+				VersionCommand: models.VersionCommand{Args: "--version", RegexVersion: `\d+\.\d+\.\d+`},
+			},
+			{FileName: "helper", CopyIt: true},
+		},
+	}
+
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dl, err := DownloadBinary(b)
+	require.NoError(t, err)
+
+	_, err = InstallDownloaded(dl)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "version mismatch")
+	assert.Contains(t, err.Error(), "were restored")
+
+	got, err := os.ReadFile(filepath.Join(installDir, "tool"))
+	require.NoError(t, err)
+	assert.Equal(t, oldTool, string(got), "the previous binary is restored")
+	assert.NoFileExists(t, filepath.Join(installDir, "helper"), "files new to the failed install are removed")
+}
+
+func TestBackupAndRollback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require elevated privileges on Windows")
+	}
+
+	downloadDir := t.TempDir()
+	installDir := t.TempDir()
+	backupDir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(installDir, "tool"), []byte("v1"), 0o755))
+	require.NoError(t, os.Symlink("tool", filepath.Join(installDir, "t")))
+	require.NoError(t, os.WriteFile(filepath.Join(installDir, "unrelated"), []byte("keep"), 0o644))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(downloadDir, "lib"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(downloadDir, "tool"), []byte("v2"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(downloadDir, "lib", "libtool.so"), []byte("lib"), 0o644))
+	require.NoError(t, os.Symlink("lib/libtool.so", filepath.Join(downloadDir, "t")))
+
+	b := models.Binaries{
+		Name:            "tool",
+		DownloadFolder:  downloadDir,
+		InstallLocation: installDir,
+		BackupDir:       backupDir,
+		Files:           []models.File{{FileName: "*", CopyIt: true}},
+	}
+	require.NoError(t, moveFiles(&b))
+
+	got, err := os.ReadFile(filepath.Join(backupDir, "tool"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(got))
+	target, err := os.Readlink(filepath.Join(backupDir, "t"))
+	require.NoError(t, err)
+	assert.Equal(t, "tool", target)
+	assert.NoFileExists(t, filepath.Join(backupDir, "unrelated"), "only replaced files are backed up")
+
+	installed, err := InstalledBinaryFor(b)
+	require.NoError(t, err)
+
+	t.Run("without_previous_version", func(t *testing.T) {
+		_, err := Rollback(installed)
+		require.Error(t, err)
+	})
+
+	// A file of the previous version the new one doesn't ship
+	extra := filepath.Join(installDir, "tool-helper")
+	require.NoError(t, os.WriteFile(extra, []byte("helper"), 0o755))
+
+	installed.Backup = backupDir
+	installed.Previous = &models.InstalledBinary{
+		Name:            "tool",
+		Version:         "1.0.0",
+		InstallLocation: installDir,
+		Files: []models.InstalledFile{
+			{Path: filepath.Join(installDir, "tool"), SHA256: "stale"},
+			{Path: filepath.Join(installDir, "t"), Symlink: "tool"},
+			{Path: extra, SHA256: "helper-sum"},
+		},
+	}
+
+	previous, err := Rollback(installed)
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", previous.Version)
+	require.Len(t, previous.Files, 3)
+	assert.Contains(t, previous.Files, models.InstalledFile{Path: extra, SHA256: "helper-sum"}, "untouched files of the previous version stay recorded")
+	for _, f := range previous.Files {
+		assert.NotEqual(t, "stale", f.SHA256, "restored files are hashed again")
+	}
+
+	got, err = os.ReadFile(filepath.Join(installDir, "tool"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(got))
+	target, err = os.Readlink(filepath.Join(installDir, "t"))
+	require.NoError(t, err)
+	assert.Equal(t, "tool", target)
+	assert.NoDirExists(t, filepath.Join(installDir, "lib"), "directories created by the install are removed")
+	assert.FileExists(t, filepath.Join(installDir, "unrelated"))
+	assert.NoDirExists(t, backupDir, "the backup is removed after the rollback")
+}
+
+func TestRemoveStaleFiles(t *testing.T) {
+	installDir := t.TempDir()
+
+	// install installs files, by name and content, into installDir and returns the state entry
+	install := func(backupDir string, files map[string]string) models.InstalledBinary {
+		downloadDir := t.TempDir()
+		for name, content := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(downloadDir, name), []byte(content), 0o755))
+		}
+		b := models.Binaries{
+			Name:            "tool",
+			DownloadFolder:  downloadDir,
+			InstallLocation: installDir,
+			BackupDir:       backupDir,
+			Files:           []models.File{{FileName: "*", CopyIt: true}},
+		}
+		require.NoError(t, moveFiles(&b))
+		installed, err := InstalledBinaryFor(b)
+		require.NoError(t, err)
+		installed.Backup = backupDir
+		return installed
+	}
+
+	v1 := install("", map[string]string{"a": "a1", "b": "b1"})
+	backupDir := t.TempDir()
+	v2 := install(backupDir, map[string]string{"a": "a2"})
+
+	require.NoError(t, RemoveStaleFiles(v1, v2))
+	assert.NoFileExists(t, filepath.Join(installDir, "b"), "files the new version doesn't ship are removed")
+	assert.FileExists(t, filepath.Join(backupDir, "b"), "and backed up for a rollback")
+
+	require.NoError(t, Uninstall(v2, false))
+	assert.NoFileExists(t, filepath.Join(installDir, "a"))
+	assert.NoFileExists(t, filepath.Join(installDir, "b"))
+}
+
+func TestMoveFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	require.NoError(t, os.WriteFile(src, []byte("data"), 0o640))
+
+	require.NoError(t, moveFile(src, dst))
+	assert.NoFileExists(t, src)
+	info, err := os.Stat(dst)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+}
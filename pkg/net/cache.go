@@ -0,0 +1,96 @@
+package net
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg/fileio"
+	"github.com/akshaybabloo/binstall/pkg/utils"
+)
+
+// Offline makes DownloadBinary take assets only from the download cache, it
+// fails with pkg.ErrDownloadNotCached for assets that aren't cached
+var Offline bool
+
+// cachedDownload copies the cached asset of b into the download folder, as
+// downloadFile would have downloaded it. The asset is looked up by the
+// sha256 of b, or by its download URL and release tag when the checksum
+// isn't known, as URLs like .../latest/download/tool.tar.gz serve a
+// different asset for every release. ok is false when the asset isn't cached.
+func cachedDownload(b models.Binaries, dir string) (dl models.Binaries, ok bool, err error) {
+	sum := ""
+	if b.Sha.ShaType == "" || b.Sha.ShaType == "sha256" {
+		sum = strings.ToLower(strings.TrimSpace(b.Sha.Checksum))
+	}
+	if sum == "" {
+		entry, ok, err := fileio.FindCached(dir, b.DownloadURL)
+		if err != nil || !ok {
+			return b, false, err
+		}
+		if entry.Version == "" || entry.Version != b.NewVersion {
+			logrus.Debugf("cached asset of %s is for %q, not %q", b.Name, entry.Version, b.NewVersion)
+			return b, false, nil
+		}
+		sum = entry.SHA256
+	}
+
+	src := fileio.CachedAssetPath(dir, sum)
+	if _, err := os.Stat(src); errors.Is(err, os.ErrNotExist) {
+		return b, false, nil
+	} else if err != nil {
+		return b, false, fmt.Errorf("failed to read the download cache: %w", err)
+	}
+
+	b.DownloadFolder = filepath.Join(os.TempDir(), b.Name)
+	b.DownloadFilePath = filepath.Join(b.DownloadFolder, b.DownloadFileName)
+	if err := os.MkdirAll(b.DownloadFolder, 0755); err != nil {
+		return b, false, fmt.Errorf("failed to create the download folder for: %s - %w", b.Name, err)
+	}
+	if err := copyFileWithMode(src, b.DownloadFilePath, 0o644); err != nil {
+		return b, false, err
+	}
+
+	got, err := utils.CalculateSHA256(b.DownloadFilePath)
+	if err != nil {
+		return b, false, fmt.Errorf("failed to calculate sha256 for %s: %w", b.Name, err)
+	}
+	if got != sum {
+		// A damaged cache file is dropped so the asset is downloaded again
+		logrus.Warnf("cached asset of %s doesn't match its sha256, removing it from the cache", b.Name)
+		_ = os.Remove(src)
+		_ = os.Remove(b.DownloadFilePath)
+		return b, false, nil
+	}
+
+	if b.Sha.Checksum == "" {
+		// The asset was verified against its checksum file when it was cached
+		b.Sha = models.ShaInfo{ShaType: "sha256", Checksum: sum}
+	}
+	logrus.Debugf("using the cached asset of %s: %s", b.Name, src)
+	return b, true, nil
+}
+
+// cacheDownload adds the asset downloaded and verified by DownloadBinary to
+// the download cache
+func cacheDownload(b models.Binaries, dir string) error {
+	info, err := os.Stat(b.DownloadFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to stat the downloaded file of %s: %w", b.Name, err)
+	}
+	return fileio.AddToCache(dir, models.CacheEntry{
+		URL:      b.DownloadURL,
+		Name:     b.Name,
+		Version:  b.NewVersion,
+		Asset:    b.DownloadFileName,
+		SHA256:   b.Sha.Checksum,
+		Size:     info.Size(),
+		CachedAt: time.Now().UTC(),
+	}, b.DownloadFilePath)
+}
@@ -0,0 +1,133 @@
+package net
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg"
+	"github.com/akshaybabloo/binstall/pkg/fileio"
+	"github.com/akshaybabloo/binstall/pkg/utils"
+)
+
+func TestDownloadBinary_Cache(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+	withRetry(t, RetryPolicy{})
+
+	content := []byte("release asset")
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		_, _ = w.Write(content)
+	}))
+	t.Cleanup(srv.Close)
+
+	b := models.Binaries{
+		Name:             uniqueTempName(t),
+		NewVersion:       "v1.0.0",
+		DownloadURL:      srv.URL + "/v1/tool.tar.gz",
+		DownloadFileName: "tool.tar.gz",
+	}
+
+	first, err := DownloadBinary(b)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, hits.Load())
+
+	dir, err := fileio.CacheDir()
+	require.NoError(t, err)
+	entry, ok, err := fileio.FindCached(dir, b.DownloadURL)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, first.Sha.Checksum, entry.SHA256)
+	assert.Equal(t, b.Name, entry.Name)
+
+	t.Run("reinstall_uses_the_cache", func(t *testing.T) {
+		got, err := DownloadBinary(b)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, hits.Load())
+		assert.Equal(t, first.Sha.Checksum, got.Sha.Checksum)
+
+		data, err := os.ReadFile(got.DownloadFilePath)
+		require.NoError(t, err)
+		assert.Equal(t, content, data)
+	})
+
+	t.Run("another_release_at_the_same_url_is_downloaded", func(t *testing.T) {
+		// e.g. .../releases/latest/download/tool.tar.gz
+		newer := b
+		newer.NewVersion = "v1.1.0"
+		_, err := DownloadBinary(newer)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, hits.Load())
+
+		_, err = DownloadBinary(b)
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, hits.Load(), "the entry of the URL now points at the newer release")
+	})
+
+	t.Run("locked_checksum_finds_the_asset_from_another_url", func(t *testing.T) {
+		mirrored := b
+		mirrored.DownloadURL = "http://mirror.invalid/tool.tar.gz"
+		mirrored.Sha = models.ShaInfo{ShaType: "sha256", Checksum: first.Sha.Checksum}
+		_, err := DownloadBinary(mirrored)
+		require.NoError(t, err)
+	})
+
+	t.Run("offline_miss", func(t *testing.T) {
+		Offline = true
+		t.Cleanup(func() { Offline = false })
+
+		before := hits.Load()
+		missing := b
+		missing.DownloadURL = srv.URL + "/v2/tool.tar.gz"
+		_, err := DownloadBinary(missing)
+		require.ErrorIs(t, err, pkg.ErrDownloadNotCached)
+		assert.Equal(t, before, hits.Load())
+
+		_, err = DownloadBinary(b)
+		require.NoError(t, err, "cached assets install offline")
+	})
+
+	t.Run("damaged_asset_is_downloaded_again", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(fileio.CachedAssetPath(dir, entry.SHA256), []byte("garbage"), 0o644))
+
+		got, err := DownloadBinary(b)
+		require.NoError(t, err)
+		assert.EqualValues(t, 4, hits.Load())
+
+		sum, err := utils.CalculateSHA256(fileio.CachedAssetPath(dir, entry.SHA256))
+		require.NoError(t, err)
+		assert.Equal(t, got.Sha.Checksum, sum, "the cache holds the downloaded asset again")
+	})
+}
+
+func TestInstalledLockEntry(t *testing.T) {
+	installed := models.InstalledBinary{
+		Name:        "tool",
+		Tag:         "v1.2.0",
+		DownloadURL: "https://example.test/v1.2.0/tool_linux_amd64.tar.gz",
+		SHA256:      "abc",
+		OS:          "linux",
+		Arch:        "amd64",
+	}
+	entry := InstalledLockEntry(installed)
+	assert.Equal(t, models.LockEntry{
+		Name:   "tool",
+		OS:     "linux",
+		Arch:   "amd64",
+		Tag:    "v1.2.0",
+		URL:    installed.DownloadURL,
+		Asset:  "tool_linux_amd64.tar.gz",
+		SHA256: "abc",
+	}, entry, "the asset of older states comes from the URL")
+
+	installed.Asset = "tool.tar.gz"
+	assert.Equal(t, "tool.tar.gz", InstalledLockEntry(installed).Asset)
+}
@@ -1,28 +1,34 @@
 package net
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"mime"
+	"net/http"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
-	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/sirupsen/logrus"
 
 	"github.com/akshaybabloo/binstall/pkg"
 
-	"github.com/go-resty/resty/v2"
 	"github.com/google/go-github/v89/github"
 	"github.com/hashicorp/go-version"
 	"golift.io/xtractr"
 
 	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg/fileio"
 	"github.com/akshaybabloo/binstall/pkg/utils"
 )
 
@@ -30,53 +36,151 @@ import (
 // ==============================  CHECK UPDATES  ================================
 // ===============================================================================
 
+// Providers that can be set with the provider key of a binary config
 const (
-	GitHub = iota + 1
-	Others
+	GitHub = "github"
+	GitLab = "gitlab"
+	Gitea  = "gitea"
+	Others = "others"
 )
 
-var ignoreFileExt = []string{".deb", ".sig", ".rpm", ".pem", ".sbom"}
 var allowedMediaTypes = []string{"application/gzip", "application/zip", "application/x-bzip1-compressed-tar", "application/x-bzip-compressed-tar", "raw", "application/x-gtar", "application/octet-stream", "application/x-xz"}
 
-// newGitHubClient builds the github client used by checkForNewVersion.
+// newGitHubClient builds the github client used by checkForNewVersion. The
+// client targets api.github.com unless enterprise endpoints are given, and
+// retries failed requests following Retry.
 // Exposed as a var so tests can substitute a client pointing at httptest.
-var newGitHubClient = func(token string) (*github.Client, error) {
+var newGitHubClient = func(token string, enterprise models.GitHubEnterprise) (*github.Client, error) {
+	opts := []github.ClientOptionsFunc{github.WithTransport(&retryTransport{base: http.DefaultTransport})}
 	if token != "" {
-		return github.NewClient(github.WithAuthToken(token))
+		opts = append(opts, github.WithAuthToken(token))
 	}
-	return github.NewClient()
+	if enterprise.BaseURL != "" {
+		uploadURL := enterprise.UploadURL
+		if uploadURL == "" {
+			uploadURL = enterprise.BaseURL
+		}
+		opts = append(opts, github.WithEnterpriseURLs(enterprise.BaseURL, uploadURL))
+	}
+	return github.NewClient(opts...)
+}
+
+// gitHubEnterpriseFor returns the enterprise endpoints to use for b. Binaries
+// hosted on github.com always use the public API, even when enterprise
+// endpoints are configured globally.
+func gitHubEnterpriseFor(b models.Binaries) models.GitHubEnterprise {
+	if strings.Contains(b.URL, "github.com") {
+		return models.GitHubEnterprise{}
+	}
+	return b.GitHubEnterprise
+}
+
+// WithGitHubEnterprise returns b with the GitHub Enterprise endpoints to use
+// when it doesn't declare its own: baseURL and uploadURL, usually given with
+// the --github-enterprise-url and --github-enterprise-upload-url flags,
+// falling back to GITHUB_ENTERPRISE_URL and GITHUB_ENTERPRISE_UPLOAD_URL.
+func WithGitHubEnterprise(b models.Binaries, baseURL, uploadURL string) models.Binaries {
+	if b.GitHubEnterprise.BaseURL != "" {
+		return b
+	}
+	if baseURL == "" {
+		baseURL = os.Getenv("GITHUB_ENTERPRISE_URL")
+	}
+	if uploadURL == "" {
+		uploadURL = os.Getenv("GITHUB_ENTERPRISE_UPLOAD_URL")
+	}
+	if baseURL != "" {
+		b.GitHubEnterprise = models.GitHubEnterprise{BaseURL: baseURL, UploadURL: uploadURL}
+	}
+	return b
 }
 
 // resolveDownloadFileName checks the Binaries.Download config for an explicit file name
-// matching the current OS/arch. Returns the rendered file name, or empty string if none configured.
+// matching the target OS/arch. Returns the rendered file name, or empty string if none configured.
+//
+// "*" can be used as the OS or arch key to cover every platform with one
+// template. Exact keys win over "*", and the OS key is matched before the arch key.
 func resolveDownloadFileName(b models.Binaries, tagName string) string {
 	if b.Download == nil {
 		return ""
 	}
 
-	archMap, ok := b.Download[runtime.GOOS]
-	if !ok {
-		return ""
-	}
+	target := TargetFor(b)
+	for _, osKey := range []string{target.OS, "*"} {
+		archMap, ok := b.Download[osKey]
+		if !ok {
+			continue
+		}
 
-	// Look up arch entry, normalizing the config key to match runtime.GOARCH
-	for archKey, info := range archMap {
-		if utils.NormalizeArch(archKey) == runtime.GOARCH {
-			if info.FileName == "" {
-				continue
+		if rendered := resolveDownloadArch(b, archMap, target, tagName); rendered != "" {
+			return rendered
+		}
+
+		if info, ok := archMap["*"]; ok {
+			if rendered := renderDownloadFileName(b, info, tagName); rendered != "" {
+				return rendered
 			}
-			rendered, err := utils.RenderDownloadTemplate(info.FileName, tagName)
-			if err != nil {
-				logrus.Warnf("Failed to render download template for %s: %v", b.Name, err)
-				continue
+		}
+	}
+
+	return ""
+}
+
+// resolveDownloadArch renders the entry of archMap for the target arch. A key
+// naming the exact target, e.g. armv7 on armv7, wins over keys that only
+// match the architecture once normalized, e.g. armv6 or arm. Of those the
+// newest arm variant the target can run is tried first. Keys are tried in a
+// fixed order so the same entry is picked on every run.
+func resolveDownloadArch(b models.Binaries, archMap map[string]models.DownloadArchInfo, target models.OSArch, tagName string) string {
+	var fallback []string
+	for _, archKey := range slices.Sorted(maps.Keys(archMap)) {
+		if archKey == "*" {
+			continue
+		}
+		arch, variant := utils.ParseArch(archKey)
+		if arch == target.Arch && variant == target.Variant {
+			if rendered := renderDownloadFileName(b, archMap[archKey], tagName); rendered != "" {
+				return rendered
 			}
-			return rendered
+			continue
+		}
+		if utils.NormalizeArch(archKey) != target.Arch {
+			continue
+		}
+		if variant != "" && target.Variant != "" && variant > target.Variant {
+			// e.g. armv7 doesn't run on armv6
+			continue
 		}
+		fallback = append(fallback, archKey)
 	}
 
+	slices.SortStableFunc(fallback, func(a, b string) int {
+		_, va := utils.ParseArch(a)
+		_, vb := utils.ParseArch(b)
+		return cmp.Compare(vb, va)
+	})
+	for _, archKey := range fallback {
+		if rendered := renderDownloadFileName(b, archMap[archKey], tagName); rendered != "" {
+			return rendered
+		}
+	}
 	return ""
 }
 
+// renderDownloadFileName renders the file name template of a Download entry,
+// returning an empty string if it is empty or fails to render
+func renderDownloadFileName(b models.Binaries, info models.DownloadArchInfo, tagName string) string {
+	if info.FileName == "" {
+		return ""
+	}
+	rendered, err := renderTemplate(b, info.FileName, tagName)
+	if err != nil {
+		logrus.Warnf("Failed to render download template for %s: %v", b.Name, err)
+		return ""
+	}
+	return rendered
+}
+
 func getCurrentVersion(b models.Binaries) (models.Binaries, error) {
 	for i := range b.Files {
 		file := &b.Files[i]
@@ -99,74 +203,528 @@ func getCurrentVersion(b models.Binaries) (models.Binaries, error) {
 	return b, nil
 }
 
-func findProvider(b models.Binaries) models.Binaries {
-	if strings.Contains(b.URL, "github.com") {
+// findProvider sets the provider of b. An explicitly configured provider is
+// kept (forgejo and codeberg are accepted as Gitea), otherwise it is detected
+// from the URL host. Unknown providers are an error.
+func findProvider(b models.Binaries) (models.Binaries, error) {
+	if b.Provider != "" {
+		b.Provider = models.Provider(strings.ToLower(strings.TrimSpace(string(b.Provider))))
+		switch b.Provider {
+		case "forgejo", "codeberg":
+			b.Provider = Gitea
+		case GitHub, GitLab, Gitea, Others:
+		default:
+			return models.Binaries{}, fmt.Errorf("unsupported provider %q for %s, use github, gitlab, gitea or others", b.Provider, b.Name)
+		}
+		return b, nil
+	}
+
+	if strings.Contains(b.URL, "github.com") || utils.SameHost(b.URL, b.GitHubEnterprise.BaseURL) {
 		b.Provider = GitHub
+	} else if utils.IsGitLabURL(b.URL) {
+		b.Provider = GitLab
+	} else if utils.IsGiteaURL(b.URL) {
+		b.Provider = Gitea
 	} else {
 		b.Provider = Others
 	}
-	return b
+	return b, nil
 }
 
-func checkForNewVersion(b models.Binaries, a ...string) (models.Binaries, error) {
-	if b.Provider == GitHub {
-		info := utils.ExpandGitHubURL(b.URL)
+// Release channels that can be followed with the channel key of a binary config
+const (
+	ChannelStable     = "stable"
+	ChannelPrerelease = "prerelease"
+	ChannelNightly    = "nightly"
+)
+
+// maxReleasePages caps how many pages of releases are listed when resolving a pinned version
+const maxReleasePages = 10
+
+// release is the provider independent view of a release that asset selection works on
+type release struct {
+	TagName    string
+	Prerelease bool
+	Assets     []releaseAsset
+
+	// StrippedTag is TagName without the tag prefix or pattern of the binary, if any
+	StrippedTag string
+}
+
+// versionTag returns the part of the tag that is parsed as a version
+func (r release) versionTag() string {
+	if r.StrippedTag != "" {
+		return r.StrippedTag
+	}
+	return r.TagName
+}
+
+// releaseAsset is a single downloadable file attached to a release
+type releaseAsset struct {
+	Name        string
+	URL         string
+	ContentType string
+	Size        int64
+}
+
+// latestGitHubRelease fetches the latest GitHub release for b
+func latestGitHubRelease(b models.Binaries, token string) (release, error) {
+	info := utils.ExpandGitHubURL(b.URL)
+
+	c, err := newGitHubClient(token, gitHubEnterpriseFor(b))
+	if err != nil {
+		return release{}, err
+	}
+
+	latest, _, err := c.Repositories.GetLatestRelease(context.Background(), info.Owner, info.Repo)
+	if err != nil {
+		return release{}, err
+	}
+
+	return gitHubToRelease(latest), nil
+}
+
+// listGitHubReleases lists the published GitHub releases for b, newest first
+func listGitHubReleases(b models.Binaries, token string) ([]release, error) {
+	info := utils.ExpandGitHubURL(b.URL)
+
+	c, err := newGitHubClient(token, gitHubEnterpriseFor(b))
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []release
+	opts := &github.ListOptions{PerPage: 100}
+	for page := 0; page < maxReleasePages; page++ {
+		list, resp, err := c.Repositories.ListReleases(context.Background(), info.Owner, info.Repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range list {
+			if r.GetDraft() {
+				continue
+			}
+			releases = append(releases, gitHubToRelease(r))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return releases, nil
+}
 
-		var token string
-		if len(a) > 0 && a[0] != "" {
-			token = a[0]
-			b.Token = token
+// gitHubToRelease converts a GitHub release into a release
+func gitHubToRelease(r *github.RepositoryRelease) release {
+	rel := release{TagName: r.GetTagName(), Prerelease: r.GetPrerelease()}
+	for _, asset := range r.Assets {
+		rel.Assets = append(rel.Assets, releaseAsset{
+			Name:        asset.GetName(),
+			URL:         asset.GetBrowserDownloadURL(),
+			ContentType: asset.GetContentType(),
+			Size:        int64(asset.GetSize()),
+		})
+	}
+	return rel
+}
+
+// latestRelease fetches the latest release of b from its provider
+func latestRelease(b models.Binaries, token string) (release, error) {
+	switch b.Provider {
+	case GitHub:
+		return latestGitHubRelease(b, token)
+	case GitLab:
+		return latestGitLabRelease(b)
+	case Gitea:
+		return latestGiteaRelease(b)
+	default:
+		return release{}, fmt.Errorf("unsupported provider %q for %s", b.Provider, b.Name)
+	}
+}
+
+// listReleases lists the releases of b from its provider, newest first
+func listReleases(b models.Binaries, token string) ([]release, error) {
+	switch b.Provider {
+	case GitHub:
+		return listGitHubReleases(b, token)
+	case GitLab:
+		return listGitLabReleases(b)
+	case Gitea:
+		return listGiteaReleases(b)
+	default:
+		return nil, fmt.Errorf("unsupported provider %q for %s", b.Provider, b.Name)
+	}
+}
+
+// pickRelease returns the release matching the pinned version want. A release
+// whose tag equals want (ignoring a leading "v") wins, otherwise want is
+// parsed as a version constraint, e.g. "~> 1.4" or ">= 2.0, < 3", and the
+// highest matching release is returned. Prereleases are only considered when
+// includePrerelease is set, and are then matched on their core version.
+func pickRelease(releases []release, want string, includePrerelease bool) (release, error) {
+	want = strings.TrimSpace(want)
+	for _, rel := range releases {
+		if rel.TagName == want || strings.TrimPrefix(rel.versionTag(), "v") == strings.TrimPrefix(want, "v") {
+			return rel, nil
 		}
-		c, err := newGitHubClient(token)
+	}
+
+	constraints, err := version.NewConstraint(want)
+	if err != nil {
+		return release{}, fmt.Errorf("no release tagged %q and it is not a valid version constraint: %w", want, err)
+	}
+
+	var best release
+	var bestVersion *version.Version
+	for _, rel := range releases {
+		v, err := version.NewVersion(utils.NormalizeLetterSuffix(rel.versionTag()))
 		if err != nil {
-			return models.Binaries{}, err
+			logrus.Debugf("Skipping release %s: %v", rel.TagName, err)
+			continue
+		}
+		isPrerelease := rel.Prerelease || v.Prerelease() != ""
+		if isPrerelease && !includePrerelease {
+			continue
+		}
+
+		matches := constraints.Check(v)
+		if isPrerelease {
+			matches = constraints.Check(v.Core())
 		}
+		if matches && (bestVersion == nil || v.GreaterThan(bestVersion)) {
+			best, bestVersion = rel, v
+		}
+	}
+	if bestVersion == nil {
+		return release{}, fmt.Errorf("no release matches version %q", want)
+	}
+	return best, nil
+}
+
+// newestRelease returns the release with the highest version. Prereleases are
+// only considered when includePrerelease is set. If no tag can be parsed as a
+// version the first listed release is used.
+func newestRelease(releases []release, includePrerelease bool) (release, error) {
+	var candidates []release
+	for _, rel := range releases {
+		if includePrerelease || !rel.Prerelease {
+			candidates = append(candidates, rel)
+		}
+	}
+	if len(candidates) == 0 {
+		return release{}, errors.New("no releases found")
+	}
 
-		releases, _, err := c.Repositories.GetLatestRelease(context.Background(), info.Owner, info.Repo)
+	best := candidates[0]
+	var bestVersion *version.Version
+	for _, rel := range candidates {
+		v, err := version.NewVersion(utils.NormalizeLetterSuffix(rel.versionTag()))
 		if err != nil {
-			return models.Binaries{}, err
+			continue
+		}
+		if !includePrerelease && v.Prerelease() != "" {
+			continue
+		}
+		if bestVersion == nil || v.GreaterThan(bestVersion) {
+			best, bestVersion = rel, v
+		}
+	}
+	return best, nil
+}
+
+// nightlyRelease returns the newest release whose tag contains "nightly"
+func nightlyRelease(releases []release) (release, error) {
+	for _, rel := range releases {
+		if strings.Contains(strings.ToLower(rel.TagName), ChannelNightly) {
+			return rel, nil
 		}
+	}
+	return release{}, errors.New("no nightly release found")
+}
+
+// releaseChannel returns the normalised channel of b, stable by default
+func releaseChannel(b models.Binaries) string {
+	channel := strings.ToLower(strings.TrimSpace(b.Channel))
+	if channel == "" {
+		return ChannelStable
+	}
+	return channel
+}
 
-		// Check if there's a configured download entry for the current OS/arch
-		downloadFileName := resolveDownloadFileName(b, releases.GetTagName())
+// isRollingNightly reports whether b follows a nightly tag that can't be
+// ordered as a version, so the installed build can't be compared with it
+func isRollingNightly(b models.Binaries) bool {
+	if releaseChannel(b) != ChannelNightly {
+		return false
+	}
+	_, err := version.NewVersion(utils.NormalizeLetterSuffix(tagVersion(b, b.NewVersion)))
+	return err != nil
+}
 
-		if downloadFileName != "" {
-			// Use the configured download file name to find the matching asset
-			for _, asset := range releases.Assets {
-				if asset.GetName() == downloadFileName {
-					b.DownloadURL = asset.GetBrowserDownloadURL()
-					b.NewVersion = releases.GetTagName()
-					b.DownloadFileName = asset.GetName()
-					b.ContentType = asset.GetContentType()
-					b.OsInfo = models.OSArch{OS: runtime.GOOS, Arch: runtime.GOARCH}
-					break
-				}
+// tagVersion returns the part of tag that is parsed as a version, i.e. the tag
+// without the tag prefix or pattern of b
+func tagVersion(b models.Binaries, tag string) string {
+	stripped, ok, err := utils.StripTag(tag, b.TagPrefix, b.TagPattern)
+	if err != nil || !ok {
+		return tag
+	}
+	return stripped
+}
+
+// filterReleases keeps the releases whose tag matches the tag prefix or
+// pattern of b and records the stripped tag of each
+func filterReleases(b models.Binaries, releases []release) ([]release, error) {
+	var filtered []release
+	for _, rel := range releases {
+		stripped, ok, err := utils.StripTag(rel.TagName, b.TagPrefix, b.TagPattern)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		rel.StrippedTag = stripped
+		filtered = append(filtered, rel)
+	}
+	if len(filtered) == 0 {
+		return nil, fmt.Errorf("no release tags match the tag prefix or pattern for %s", b.Name)
+	}
+	return filtered, nil
+}
+
+// renderTemplate renders a download template of b for the release tag
+func renderTemplate(b models.Binaries, tmpl, tag string) (string, error) {
+	stripped := tagVersion(b, tag)
+	target := TargetFor(b)
+	return utils.RenderTemplate(tmpl, utils.TemplateData{
+		Name:            b.Name,
+		Version:         tag,
+		StrippedVersion: stripped,
+		VersionNoV:      strings.TrimPrefix(stripped, "v"),
+		OS:              target.OS,
+		Arch:            target.Arch,
+		ArchAlias:       utils.ArchAlias(target.Arch),
+		Libc:            target.Libc,
+	})
+}
+
+// resolveRelease returns the release to install for b: the latest release of
+// its channel, or the release matching the pinned Version
+func resolveRelease(b models.Binaries, token string) (release, error) {
+	channel := releaseChannel(b)
+	filterTags := b.TagPrefix != "" || b.TagPattern != ""
+	if b.Version == "" && channel == ChannelStable && !filterTags {
+		return latestRelease(b, token)
+	}
+
+	releases, err := listReleases(b, token)
+	if err != nil {
+		return release{}, err
+	}
+	if filterTags {
+		// The provider's latest release may belong to another component,
+		// so the newest one is picked from the filtered list instead
+		releases, err = filterReleases(b, releases)
+		if err != nil {
+			return release{}, err
+		}
+	}
+
+	switch channel {
+	case ChannelStable:
+		if b.Version == "" {
+			return newestRelease(releases, false)
+		}
+		return pickRelease(releases, b.Version, false)
+	case ChannelPrerelease:
+		if b.Version != "" {
+			return pickRelease(releases, b.Version, true)
+		}
+		return newestRelease(releases, true)
+	case ChannelNightly:
+		return nightlyRelease(releases)
+	default:
+		return release{}, fmt.Errorf("unsupported channel %q for %s", b.Channel, b.Name)
+	}
+}
+
+// selectAsset picks the asset of rel to download for the current OS/arch and
+// records it on b. The configured Download file name wins, then AssetPattern,
+// and otherwise the asset with the best score from scoreAssets is used. Assets
+// matching AssetExclude are never picked by the pattern or the scoring.
+func selectAsset(b models.Binaries, rel release) (models.Binaries, error) {
+	// Check if there's a configured download entry for the current OS/arch
+	downloadFileName := resolveDownloadFileName(b, rel.TagName)
+
+	if downloadFileName != "" {
+		// Use the configured download file name to find the matching asset
+		for _, asset := range rel.Assets {
+			if asset.Name == downloadFileName {
+				return useAsset(b, rel, asset, TargetFor(b)), nil
 			}
 		}
+	}
 
-		// Fall back to auto-detection if no download URL was resolved
-		// (either no download config or configured filename not found in assets)
-		if b.DownloadURL == "" {
-			for _, asset := range releases.Assets {
-				osArch := utils.FigureOutOSAndArch(asset.GetName())
-				ext := filepath.Ext(asset.GetName())
-				if runtime.GOOS == osArch.OS && runtime.GOARCH == osArch.Arch && !utils.Contains(ignoreFileExt, ext) {
-					b.DownloadURL = asset.GetBrowserDownloadURL()
-					b.NewVersion = releases.GetTagName()
-					b.DownloadFileName = asset.GetName()
-					b.ContentType = asset.GetContentType()
-					b.OsInfo = osArch
-					break
-				}
+	candidates, err := excludeAssets(b, rel.Assets)
+	if err != nil {
+		return models.Binaries{}, err
+	}
+
+	if b.AssetPattern != "" {
+		matched, err := matchAssetPattern(b, rel, candidates)
+		if err != nil {
+			return models.Binaries{}, err
+		}
+		switch len(matched) {
+		case 0:
+			logrus.Debugf("No asset of %s %s matches %q, falling back to auto-detection", b.Name, rel.TagName, b.AssetPattern)
+		case 1:
+			return useAsset(b, rel, matched[0], TargetFor(b)), nil
+		default:
+			var names []string
+			for _, asset := range matched {
+				names = append(names, asset.Name)
 			}
+			return models.Binaries{}, fmt.Errorf("%w for %s: %s", pkg.ErrNetAmbiguousAsset, b.Name, strings.Join(names, ", "))
+		}
+	}
+
+	// Fall back to auto-detection if no download URL was resolved
+	// (either no download config or configured filename not found in assets)
+	scores, err := scoreAssets(b, rel, TargetFor(b))
+	if err != nil {
+		return models.Binaries{}, err
+	}
+	if i := bestAsset(scores); i != -1 {
+		logrus.Debugf("Picked %s for %s with score %d: %s", scores[i].Name, b.Name, scores[i].Score, strings.Join(scores[i].Reasons, ", "))
+		return useAsset(b, rel, rel.Assets[i], scores[i].OSArch), nil
+	}
+
+	return b, nil
+}
+
+// useAsset records asset of rel as the download of b
+func useAsset(b models.Binaries, rel release, asset releaseAsset, osArch models.OSArch) models.Binaries {
+	b.DownloadURL = asset.URL
+	b.NewVersion = rel.TagName
+	b.DownloadFileName = asset.Name
+	b.ContentType = asset.ContentType
+	b.DownloadSize = asset.Size
+	b.OsInfo = osArch
+	return b
+}
+
+// excludeAssets drops the assets matching any of the AssetExclude patterns of b
+func excludeAssets(b models.Binaries, assets []releaseAsset) ([]releaseAsset, error) {
+	if len(b.AssetExclude) == 0 {
+		return assets, nil
+	}
+
+	var kept []releaseAsset
+	for _, asset := range assets {
+		excluded, err := isExcluded(b, asset)
+		if err != nil {
+			return nil, err
+		}
+		if !excluded {
+			kept = append(kept, asset)
+		}
+	}
+	return kept, nil
+}
+
+// isExcluded reports whether asset matches any of the AssetExclude patterns of b
+func isExcluded(b models.Binaries, asset releaseAsset) (bool, error) {
+	for _, pattern := range b.AssetExclude {
+		ok, err := utils.MatchAssetPattern(pattern, asset.Name)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchAssetPattern returns the assets matching the AssetPattern of b. The
+// pattern is rendered as a download template first.
+func matchAssetPattern(b models.Binaries, rel release, assets []releaseAsset) ([]releaseAsset, error) {
+	pattern, err := renderTemplate(b, b.AssetPattern, rel.TagName)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []releaseAsset
+	for _, asset := range assets {
+		ok, err := utils.MatchAssetPattern(pattern, asset.Name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, asset)
 		}
 	}
+	return matched, nil
+}
+
+func checkForNewVersion(b models.Binaries, a ...string) (models.Binaries, error) {
+	var token string
+	if len(a) > 0 && a[0] != "" {
+		token = a[0]
+		b.Token = token
+	}
+
+	if b.Provider == Others {
+		return checkGenericVersion(b)
+	}
+
+	rel, err := resolveRelease(b, token)
+	if err != nil {
+		return models.Binaries{}, err
+	}
+
+	b, err = selectAsset(b, rel)
+	if err != nil {
+		return models.Binaries{}, err
+	}
 	if b.DownloadURL == "" {
 		return models.Binaries{}, pkg.ErrNetBinaryNotFound
 	}
 	return b, nil
 }
 
+// ResolveBinary finds the provider of b and resolves the release and asset
+// to install, without looking at the installed version. Returns
+// pkg.ErrNetBinaryNotFound when no asset matches the target OS and arch.
+func ResolveBinary(b models.Binaries, a ...string) (models.Binaries, error) {
+	b, err := findProvider(b)
+	if err != nil {
+		return models.Binaries{}, err
+	}
+	return checkForNewVersion(b, a...)
+}
+
+// resolveFunc resolves the release and asset to install for a binary
+type resolveFunc func(b models.Binaries) (models.Binaries, error)
+
+// checkNewInstall resolves the release to install for a binary whose
+// installed version is unknown, so it is always installed
+func checkNewInstall(b models.Binaries, currentVersion string, resolve resolveFunc) (models.Binaries, error) {
+	checkV, err := resolve(b)
+	if err != nil {
+		if errors.Is(err, pkg.ErrNetBinaryNotFound) {
+			logrus.Debugf("No binary found for the target OS and Arch: %s\n", b.Name)
+			return models.Binaries{}, nil
+		}
+		return models.Binaries{}, err
+	}
+
+	checkV.CurrentVersion = currentVersion
+	checkV.UpdatesAvailable = true
+	return checkV, nil
+}
+
 // CheckUpdates Does four things:
 //
 // 1. Get the current version of the binary
@@ -174,31 +732,54 @@ func checkForNewVersion(b models.Binaries, a ...string) (models.Binaries, error)
 // 3. Check for the new version of the binary
 // 4. Compare the current version with the new version
 func CheckUpdates(b models.Binaries, a ...string) (models.Binaries, error) {
+	return checkUpdates(b, func(b models.Binaries) (models.Binaries, error) {
+		return ResolveBinary(b, a...)
+	})
+}
+
+// CheckLockedUpdates is CheckUpdates for a binary locked to entry: the locked
+// release and asset are installed whenever they differ from the installed
+// version, without resolving anything from the network
+func CheckLockedUpdates(b models.Binaries, entry models.LockEntry) (models.Binaries, error) {
+	return checkUpdates(b, func(b models.Binaries) (models.Binaries, error) {
+		return lockedBinary(b, entry), nil
+	})
+}
+
+// lockedBinary records the release and asset of entry on b. The tag is pinned
+// so it is installed even if it is older than the installed version.
+func lockedBinary(b models.Binaries, entry models.LockEntry) models.Binaries {
+	b.Version = entry.Tag
+	b.NewVersion = entry.Tag
+	b.DownloadURL = entry.URL
+	b.DownloadFileName = entry.Asset
+	b.Sha = models.ShaInfo{ShaType: "sha256", Checksum: entry.SHA256}
+	// The platform is the one the entry was locked for, the asset name only
+	// tells the arm variant and C library
+	info := utils.FigureOutOSAndArch(entry.Asset)
+	if info.Arch != entry.Arch {
+		info.Variant = ""
+	}
+	b.OsInfo = models.OSArch{OS: entry.OS, Arch: entry.Arch, Variant: info.Variant, Libc: info.Libc}
+	return b
+}
+
+func checkUpdates(b models.Binaries, resolve resolveFunc) (models.Binaries, error) {
+	if !isHostTarget(b) {
+		// Binaries for another platform can't be executed to read the installed version
+		return checkNewInstall(b, "Not Checked", resolve)
+	}
+
 	_version, err := getCurrentVersion(b)
 	if err != nil {
 		// If not found, install the binary
 		if errors.Is(err, exec.ErrNotFound) {
-			pr := findProvider(b)
-			checkV, err := checkForNewVersion(pr, a...)
-			if err != nil {
-				if errors.Is(err, pkg.ErrNetBinaryNotFound) {
-					logrus.Debugf("No binary found for the current OS and Arch: %s\n", b.Name)
-					return models.Binaries{}, nil
-				}
-				return models.Binaries{}, err
-			}
-
-			b = checkV
-			b.CurrentVersion = "Not Found"
-			b.UpdatesAvailable = true
-
-			return b, nil
+			return checkNewInstall(b, "Not Found", resolve)
 		}
 		return models.Binaries{}, err
 	}
 
-	pr := findProvider(_version)
-	checkV, err := checkForNewVersion(pr, a...)
+	checkV, err := resolve(_version)
 	if err != nil {
 		if errors.Is(err, pkg.ErrNetBinaryNotFound) {
 			logrus.Debugf("No binary found for the current OS and Arch: %s\n", b.Name)
@@ -210,6 +791,12 @@ func CheckUpdates(b models.Binaries, a ...string) (models.Binaries, error) {
 		checkV.CurrentVersion = "0.0.0"
 	}
 
+	if isRollingNightly(checkV) {
+		// Rolling nightly tags carry no version to compare with, so they are always reinstalled
+		checkV.UpdatesAvailable = true
+		return checkV, nil
+	}
+
 	// Normalize letter-suffix releases (e.g. tmux 3.6a -> 3.6.1) so semver
 	// comparison orders them as post-release patches, not pre-releases.
 	currentVersion, err := version.NewVersion(utils.NormalizeLetterSuffix(checkV.CurrentVersion))
@@ -217,12 +804,15 @@ func CheckUpdates(b models.Binaries, a ...string) (models.Binaries, error) {
 		return models.Binaries{}, fmt.Errorf("error parsing the current version for: %s - %w ", b.Name, err)
 	}
 
-	newVersion, err := version.NewVersion(utils.NormalizeLetterSuffix(checkV.NewVersion))
+	newVersion, err := version.NewVersion(utils.NormalizeLetterSuffix(tagVersion(checkV, checkV.NewVersion)))
 	if err != nil {
 		return models.Binaries{}, fmt.Errorf("error parsing the new version for: %s - %w ", b.Name, err)
 	}
 
-	if currentVersion.LessThan(newVersion) {
+	if checkV.Version != "" {
+		// A pinned version is installed even if it is older than the current one
+		checkV.UpdatesAvailable = !currentVersion.Equal(newVersion)
+	} else if currentVersion.LessThan(newVersion) {
 		checkV.UpdatesAvailable = true
 	} else {
 		checkV.UpdatesAvailable = false
@@ -240,14 +830,192 @@ func downloadFile(b models.Binaries) (models.Binaries, error) {
 	b.DownloadFolder = filepath.Join(os.TempDir(), b.Name)
 	b.DownloadFilePath = filepath.Join(b.DownloadFolder, b.DownloadFileName)
 
-	client := resty.New()
-	_, err := client.R().SetOutput(b.DownloadFilePath).Get(b.DownloadURL)
+	if err := os.MkdirAll(b.DownloadFolder, 0755); err != nil {
+		return models.Binaries{}, fmt.Errorf("failed to create the download folder for: %s - %w", b.Name, err)
+	}
+
+	out, err := os.Create(b.DownloadFilePath)
 	if err != nil {
-		return models.Binaries{}, fmt.Errorf("failed to download the file for: %s - %s", b.Name, err.Error())
+		return models.Binaries{}, fmt.Errorf("failed to create the download file for: %s - %w", b.Name, err)
+	}
+	err = downloadTo(b, out)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(b.DownloadFilePath)
+		return models.Binaries{}, err
 	}
 	return b, nil
 }
 
+// downloadTo writes the asset of b to out. Failed requests are retried
+// following Retry, and a download that breaks off mid-stream is resumed with
+// a Range request, or started over if the server doesn't support them.
+func downloadTo(b models.Binaries, out *os.File) error {
+	// The shared client without its retry transport, the loop below retries
+	// and resumes the download itself
+	client := newRestyClient()
+	if rt, ok := client.GetClient().Transport.(*retryTransport); ok {
+		client.SetTransport(rt.base)
+	}
+
+	var written int64
+	size := int64(-1) // Size of the asset, when the server reported it
+	for attempt := 1; ; attempt++ {
+		req := client.R().SetDoNotParseResponse(true)
+		if written > 0 {
+			req.SetHeader("Range", fmt.Sprintf("bytes=%d-", written))
+		}
+		resp, err := req.Get(b.DownloadURL)
+
+		var retryErr error
+		var raw *http.Response
+		if err != nil {
+			retryErr = fmt.Errorf("failed to download the file for: %s - %w", b.Name, err)
+		} else {
+			raw = resp.RawResponse
+			retryErr, err = receiveDownload(b, raw, out, &written, &size)
+			_ = raw.Body.Close()
+			if err != nil {
+				return err
+			}
+			if retryErr == nil {
+				return nil
+			}
+		}
+
+		if attempt > Retry.Count {
+			return retryErr
+		}
+		wait := Retry.wait(attempt, raw)
+		logrus.Debugf("retrying the download of %s in %s, %d bytes received: %v", b.Name, wait, written, retryErr)
+		time.Sleep(wait)
+	}
+}
+
+// receiveDownload writes the body of a download response to out, continuing
+// at written when the response is the partial content asked for. It returns
+// a retryable error when the download can be retried, or err when it can't.
+func receiveDownload(b models.Binaries, resp *http.Response, out *os.File, written, size *int64) (retryErr, err error) {
+	if err := checkDownloadResponse(b, resp); err != nil {
+		if retryableStatus(resp) {
+			return err, nil
+		}
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusPartialContent {
+		start, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != *written {
+			// Not the range that was asked for, start over
+			if err := restartDownload(out, written); err != nil {
+				return nil, err
+			}
+			return fmt.Errorf("unexpected Content-Range %q for %s", resp.Header.Get("Content-Range"), b.Name), nil
+		}
+		*size = total
+	} else {
+		if *written > 0 {
+			// The server ignored the Range header and sent the whole asset
+			logrus.Debugf("%s doesn't support resuming downloads, starting over", b.DownloadURL)
+			if err := restartDownload(out, written); err != nil {
+				return nil, err
+			}
+		}
+		*size = resp.ContentLength
+	}
+
+	n, err := io.Copy(out, resp.Body)
+	*written += n
+	if err != nil {
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) {
+			return nil, fmt.Errorf("failed to write the download file for: %s - %w", b.Name, err)
+		}
+		return fmt.Errorf("failed to download the file for: %s - %w", b.Name, err), nil
+	}
+	if *size >= 0 && *written < *size {
+		return fmt.Errorf("%w for %s: the download ended after %d of %d bytes", pkg.ErrDownloadSizeMismatch, b.Name, *written, *size), nil
+	}
+	return nil, checkDownloadSize(b, *size, *written)
+}
+
+// restartDownload empties out so the download starts from the beginning
+func restartDownload(out *os.File, written *int64) error {
+	*written = 0
+	if err := out.Truncate(0); err != nil {
+		return fmt.Errorf("failed to reset the download file %s: %w", out.Name(), err)
+	}
+	if _, err := out.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to reset the download file %s: %w", out.Name(), err)
+	}
+	return nil
+}
+
+// parseContentRange parses a Content-Range header like "bytes 100-199/200".
+// total is -1 when the server doesn't know the complete size.
+func parseContentRange(header string) (start, total int64, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	rng, length, found := strings.Cut(spec, "/")
+	if !found {
+		return 0, 0, false
+	}
+	first, _, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if length == "*" {
+		return start, -1, true
+	}
+	total, err = strconv.ParseInt(length, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, total, true
+}
+
+// checkDownloadResponse rejects download responses that aren't the release
+// asset: error statuses, and web pages served for assets that aren't one,
+// e.g. a login or rate limit page
+func checkDownloadResponse(b models.Binaries, resp *http.Response) error {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		head, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &pkg.DownloadStatusError{
+			URL:        b.DownloadURL,
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       strings.TrimSpace(string(head)),
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	name := strings.ToLower(b.DownloadFileName)
+	if mediaType == "text/html" && !strings.HasSuffix(name, ".html") && !strings.HasSuffix(name, ".htm") {
+		return fmt.Errorf("%w: %s returned %s for %s", pkg.ErrDownloadUnexpectedContent, b.DownloadURL, mediaType, b.Name)
+	}
+	return nil
+}
+
+// checkDownloadSize compares the downloaded size with the Content-Length of
+// the response and the asset size reported by the release, when known
+func checkDownloadSize(b models.Binaries, contentLength, written int64) error {
+	if contentLength >= 0 && written != contentLength {
+		return fmt.Errorf("%w for %s: the download ended after %d of %d bytes", pkg.ErrDownloadSizeMismatch, b.Name, written, contentLength)
+	}
+	if b.DownloadSize > 0 && written != b.DownloadSize {
+		return fmt.Errorf("%w for %s: expected %d bytes, got %d", pkg.ErrDownloadSizeMismatch, b.Name, b.DownloadSize, written)
+	}
+	return nil
+}
+
 func verifyFile(b models.Binaries) (bool, error) {
 	// First check inline checksum
 	if b.Sha.Checksum != "" {
@@ -278,11 +1046,18 @@ func verifyFile(b models.Binaries) (bool, error) {
 			return false, fmt.Errorf("unsupported sha type %q for %s", b.Sha.ShaType, b.Name)
 		}
 
-		client := resty.New()
+		client := newRestyClient()
 		r, err := client.R().Get(b.Sha.URL)
 		if err != nil {
 			return false, fmt.Errorf("failed to get checksum file for %s: %w", b.Name, err)
 		}
+		if r.IsError() {
+			return false, fmt.Errorf("failed to get checksum file for %s: %w", b.Name, &pkg.DownloadStatusError{
+				URL:        b.Sha.URL,
+				StatusCode: r.StatusCode(),
+				Status:     r.Status(),
+			})
+		}
 
 		shaContent := string(r.Body())
 
@@ -360,8 +1135,8 @@ func uncompressFile(b models.Binaries) error {
 	return nil
 }
 
-func moveFiles(b *models.Binaries) error {
-	// Expand the ~ to the home directory
+// expandInstallLocation expands a leading ~ of the install location to the home directory
+func expandInstallLocation(b *models.Binaries) error {
 	if strings.HasPrefix(b.InstallLocation, "~/") {
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
@@ -369,16 +1144,23 @@ func moveFiles(b *models.Binaries) error {
 		}
 		b.InstallLocation = filepath.Join(homeDir, b.InstallLocation[2:])
 	}
+	return nil
+}
+
+func moveFiles(b *models.Binaries) error {
+	if err := expandInstallLocation(b); err != nil {
+		return err
+	}
 
 	// Ensure the installation location exists
-	err := os.MkdirAll(b.InstallLocation, 0755)
+	err := mkdirAll(b, b.InstallLocation, 0755)
 	if err != nil {
 		return fmt.Errorf("failed to create install directory %s: %w", b.InstallLocation, err)
 	}
 
 	for _, file := range b.Files {
 		if file.CopyIt && file.FileName == "*" {
-			if err := copyAllRecursively(*b, file); err != nil {
+			if err := copyAllRecursively(b, file); err != nil {
 				return err
 			}
 			continue
@@ -394,12 +1176,18 @@ func moveFiles(b *models.Binaries) error {
 			dstPath = filepath.Join(b.InstallLocation, file.RenameTo)
 		}
 
+		if file.CopyIt || file.ExecuteWhenCopying {
+			if err := backupFile(b, dstPath); err != nil {
+				return err
+			}
+		}
+
 		// Check version before move
 		var cmd *exec.Cmd
 		var stdout []byte
 		if file.ExecuteWhenCopying {
 
-			if file.CheckVersion || !file.CopyIt {
+			if (file.CheckVersion || !file.CopyIt) && isHostTarget(*b) {
 				cmd = exec.Command(srcPath, file.VersionCommand.Args)
 				stdout, err := cmd.CombinedOutput()
 				if err != nil {
@@ -412,21 +1200,23 @@ func moveFiles(b *models.Binaries) error {
 				return fmt.Errorf("source file does not exist: %s", srcPath)
 			}
 
-			// Remove the destination file if it exists
-			err = os.Remove(dstPath)
-			if err != nil && !os.IsNotExist(err) {
-				return fmt.Errorf("failed to remove existing file %s: %w", dstPath, err)
+			// Remove the destination file if it exists, a copied file replaces it atomically
+			if !file.CopyIt {
+				err = os.Remove(dstPath)
+				if err != nil && !os.IsNotExist(err) {
+					return fmt.Errorf("failed to remove existing file %s: %w", dstPath, err)
+				}
 			}
 		}
 
 		if file.CopyIt {
 			logrus.Debugf("Copying %s to %s\n", srcPath, dstPath)
-			// Move the file
-			err = os.Rename(srcPath, dstPath)
+			// Move the file, new files are made executable and replaced ones keep their mode
+			err = replaceFile(srcPath, dstPath, 0755)
 			if err != nil {
 				if errors.Is(err, os.ErrNotExist) {
 					srcPath = resolveSingleFileSourcePath(*b, file, srcPath)
-					err = os.Rename(srcPath, dstPath)
+					err = replaceFile(srcPath, dstPath, 0755)
 					if err != nil {
 						return fmt.Errorf("file not found even after path adjustment: %s to %s: %w", srcPath, dstPath, err)
 					}
@@ -439,21 +1229,10 @@ func moveFiles(b *models.Binaries) error {
 			if _, err := os.Stat(dstPath); os.IsNotExist(err) {
 				return fmt.Errorf("file was not successfully moved to %s", dstPath)
 			}
+			b.InstalledFiles = append(b.InstalledFiles, dstPath)
 
-			// Set execute permissions if needed
-			err = os.Chmod(dstPath, 0755)
-			if err != nil {
-				return fmt.Errorf("failed to set execute permissions on %s: %w", dstPath, err)
-			}
-
-			// Verify permissions
-			_, err = os.Stat(dstPath)
-			if err != nil {
-				return fmt.Errorf("failed to get file info for %s: %w", dstPath, err)
-			}
-
-			if file.CheckVersion {
-				// Check version after move
+			if file.CheckVersion && isHostTarget(*b) {
+				// Check version after move, binaries for another platform can't run
 				cmd = exec.Command(dstPath, file.VersionCommand.Args)
 				stdout, err = cmd.CombinedOutput()
 				if err != nil {
@@ -505,7 +1284,7 @@ func resolveConfiguredCopyRoot(b models.Binaries, file models.File) string {
 
 	root := file.CopyContentsFrom
 	if strings.Contains(root, "{{") {
-		rendered, err := utils.RenderDownloadTemplate(root, b.NewVersion)
+		rendered, err := renderTemplate(b, root, b.NewVersion)
 		if err != nil {
 			logrus.Warnf("Failed to render copyContentsFrom template for %s: %v", b.Name, err)
 			return ""
@@ -597,8 +1376,32 @@ func copyFileWithMode(srcPath, dstPath string, mode os.FileMode) error {
 	return nil
 }
 
-func copyAllRecursively(b models.Binaries, file models.File) error {
-	sourceRoot := resolveWildcardSourceRoot(b, file)
+// mkdirAll creates dir like os.MkdirAll and records the directories it had
+// to create in b.InstalledDirs, parents first, so they can be removed again
+func mkdirAll(b *models.Binaries, dir string, perm os.FileMode) error {
+	var missing []string
+	for d := filepath.Clean(dir); ; d = filepath.Dir(d) {
+		if _, err := os.Lstat(d); err == nil {
+			break
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	if err := os.MkdirAll(dir, perm); err != nil {
+		return err
+	}
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		b.InstalledDirs = append(b.InstalledDirs, missing[i])
+	}
+	return nil
+}
+
+func copyAllRecursively(b *models.Binaries, file models.File) error {
+	sourceRoot := resolveWildcardSourceRoot(*b, file)
 	if info, err := os.Stat(sourceRoot); err != nil || !info.IsDir() {
 		return fmt.Errorf("wildcard source root does not exist or is not a directory: %s", sourceRoot)
 	}
@@ -630,15 +1433,18 @@ func copyAllRecursively(b models.Binaries, file models.File) error {
 		dstPath := filepath.Join(b.InstallLocation, relPath)
 
 		if info.IsDir() {
-			if err := os.MkdirAll(dstPath, info.Mode().Perm()); err != nil {
+			if err := mkdirAll(b, dstPath, info.Mode().Perm()); err != nil {
 				return fmt.Errorf("failed to create destination directory %s: %w", dstPath, err)
 			}
 			return nil
 		}
 
-		if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+		if err := mkdirAll(b, filepath.Dir(dstPath), 0755); err != nil {
 			return fmt.Errorf("failed to create destination parent directory for %s: %w", dstPath, err)
 		}
+		if err := backupFile(b, dstPath); err != nil {
+			return err
+		}
 
 		if info.Mode()&os.ModeSymlink != 0 {
 			target, err := os.Readlink(srcPath)
@@ -651,12 +1457,14 @@ func copyAllRecursively(b models.Binaries, file models.File) error {
 			if err := os.Symlink(target, dstPath); err != nil {
 				return fmt.Errorf("failed to create symlink %s -> %s: %w", dstPath, target, err)
 			}
+			b.InstalledFiles = append(b.InstalledFiles, dstPath)
 			return nil
 		}
 
-		if err := copyFileWithMode(srcPath, dstPath, info.Mode()); err != nil {
+		if err := copyReplaceFile(srcPath, dstPath, info.Mode()); err != nil {
 			return err
 		}
+		b.InstalledFiles = append(b.InstalledFiles, dstPath)
 
 		return nil
 	})
@@ -668,6 +1476,12 @@ func copyAllRecursively(b models.Binaries, file models.File) error {
 }
 
 func verifyNewBin(b models.Binaries) error {
+	if !isHostTarget(b) {
+		logrus.Debugf("Skipping verification of %s, it is installed for %s/%s", b.Name, b.OsInfo.OS, b.OsInfo.Arch)
+		return nil
+	}
+
+	rollingNightly := isRollingNightly(b)
 	for _, file := range b.Files {
 		if !file.CheckVersion {
 			continue
@@ -695,9 +1509,17 @@ func verifyNewBin(b models.Binaries) error {
 		cmd := exec.Command(fullPath, file.VersionCommand.Args)
 		stdout, err := cmd.CombinedOutput()
 		if err != nil {
+			if host := utils.HostLibc(); b.OsInfo.Libc != "" && b.OsInfo.Libc != utils.LibcStatic && host != "" && b.OsInfo.Libc != host {
+				return fmt.Errorf("failed to execute %s, it is built for %s libc but the system uses %s (set libc: %s): %w\nOutput: %s", fullPath, b.OsInfo.Libc, host, host, err, stdout)
+			}
 			return fmt.Errorf("failed to execute %s: %w\nOutput: %s", fullPath, err, stdout)
 		}
 
+		if rollingNightly {
+			// Nothing to compare the installed version with, running it is enough
+			continue
+		}
+
 		match, err := utils.ExtractVersion(string(stdout), file.VersionCommand.RegexVersion)
 		if err != nil {
 			return fmt.Errorf("failed to compile regex for %s: %w", file.FileName, err)
@@ -708,7 +1530,7 @@ func verifyNewBin(b models.Binaries) error {
 			return fmt.Errorf("failed to parse installed version for %s: %w", file.FileName, err)
 		}
 
-		newVersion, err := version.NewVersion(utils.NormalizeLetterSuffix(strings.TrimSpace(b.NewVersion)))
+		newVersion, err := version.NewVersion(utils.NormalizeLetterSuffix(strings.TrimSpace(tagVersion(b, b.NewVersion))))
 		if err != nil {
 			return fmt.Errorf("failed to parse new version for %s: %w", file.FileName, err)
 		}
@@ -734,25 +1556,211 @@ func DownloadAndMoveFiles(b models.Binaries) error {
 		return err
 	}
 
+	_, err = InstallDownloaded(dl)
+	return err
+}
+
+// DownloadBinary downloads and verifies the asset of a resolved binary
+// without installing it. The sha256 of the asset is recorded in Sha.Checksum
+// so it can be verified again later without network access.
+//
+// Assets are taken from the download cache when they are in it, and added
+// to it after they are verified. With Offline set only the cache is used.
+func DownloadBinary(b models.Binaries) (models.Binaries, error) {
+	cacheDir, err := fileio.CacheDir()
+	if err != nil {
+		return models.Binaries{}, err
+	}
+	dl, cached, err := cachedDownload(b, cacheDir)
+	if err != nil {
+		return models.Binaries{}, err
+	}
+	if !cached {
+		if Offline {
+			return models.Binaries{}, fmt.Errorf("%w: %s from %s", pkg.ErrDownloadNotCached, b.Name, b.DownloadURL)
+		}
+		dl, err = downloadFile(b)
+		if err != nil {
+			return models.Binaries{}, err
+		}
+	}
+
 	file, err := verifyFile(dl)
 	if err != nil && !file {
-		return err
+		return models.Binaries{}, err
 	}
 
-	err = uncompressFile(dl)
+	checksum, err := utils.CalculateSHA256(dl.DownloadFilePath)
 	if err != nil {
-		return err
+		return models.Binaries{}, fmt.Errorf("failed to calculate sha256 for %s: %w", b.Name, err)
+	}
+	dl.Sha = models.ShaInfo{ShaType: "sha256", Checksum: checksum}
+
+	if !cached {
+		if err := cacheDownload(dl, cacheDir); err != nil {
+			// The cache only saves downloads, the install doesn't need it
+			logrus.Warnf("failed to cache the asset of %s: %v", b.Name, err)
+		}
+	}
+	return dl, nil
+}
+
+// InstalledLockEntry returns a lockfile entry for the asset of an installed
+// binary, so it can be installed again from the download cache
+func InstalledLockEntry(installed models.InstalledBinary) models.LockEntry {
+	asset := installed.Asset
+	if asset == "" {
+		// States written before the asset was recorded
+		asset = path.Base(installed.DownloadURL)
+	}
+	return models.LockEntry{
+		Name:   installed.Name,
+		OS:     installed.OS,
+		Arch:   installed.Arch,
+		Tag:    installed.Tag,
+		URL:    installed.DownloadURL,
+		Asset:  asset,
+		SHA256: installed.SHA256,
+	}
+}
+
+// LockEntryFor returns the lockfile entry of a binary downloaded with DownloadBinary
+func LockEntryFor(b models.Binaries) models.LockEntry {
+	t := TargetFor(b)
+	return models.LockEntry{
+		Name:   b.Name,
+		OS:     t.OS,
+		Arch:   t.Arch,
+		Tag:    b.NewVersion,
+		URL:    b.DownloadURL,
+		Asset:  b.DownloadFileName,
+		SHA256: b.Sha.Checksum,
+	}
+}
+
+// InstallDownloaded installs an asset that is already at b.DownloadFilePath:
+// it is verified, uncompressed into b.DownloadFolder, moved to the install
+// location, the new binaries are checked and their completions and man
+// pages installed. The returned binary lists the installed files in
+// InstalledFiles.
+//
+// The files the install replaces are backed up to b.BackupDir, or a
+// temporary directory when it is empty, and restored if moving or checking
+// the new files, or the postInstall hook, fails. The preInstall hook runs
+// before any file is moved.
+func InstallDownloaded(b models.Binaries) (models.Binaries, error) {
+	file, err := verifyFile(b)
+	if err != nil && !file {
+		return models.Binaries{}, err
 	}
 
-	err = moveFiles(&dl)
+	err = uncompressFile(b)
 	if err != nil {
-		return err
+		return models.Binaries{}, err
 	}
 
-	err = verifyNewBin(dl)
+	if err := expandInstallLocation(&b); err != nil {
+		return models.Binaries{}, err
+	}
+	if err := runHook("preInstall", b.Hooks.PreInstall, b.Shell, b.Hooks, hookEnvFor(b)); err != nil {
+		return models.Binaries{}, err
+	}
+
+	if b.BackupDir == "" {
+		b.BackupDir, err = os.MkdirTemp("", "binstall-backup-")
+		if err != nil {
+			return models.Binaries{}, fmt.Errorf("failed to create a backup directory: %w", err)
+		}
+		defer os.RemoveAll(b.BackupDir)
+	}
+
+	if b.Versioned {
+		err = moveVersioned(&b)
+	} else {
+		err = moveFiles(&b)
+	}
+	if err == nil {
+		err = verifyNewBin(b)
+	}
+	if err == nil {
+		err = installDocs(&b)
+	}
+	if err == nil {
+		err = runHook("postInstall", b.Hooks.PostInstall, b.Shell, b.Hooks, hookEnvFor(b))
+	}
 	if err != nil {
-		return err
+		if _, rerr := restoreFiles(b.InstallLocation, b.BackupDir, b.InstalledFiles, b.InstalledDirs); rerr != nil {
+			return models.Binaries{}, errors.Join(err, fmt.Errorf("failed to restore the previous files of %s: %w", b.Name, rerr))
+		}
+		return models.Binaries{}, fmt.Errorf("%w\nThe previous files of %s were restored", err, b.Name)
 	}
 
-	return nil
+	return b, nil
+}
+
+// InstalledBinaryFor returns the install state entry of a binary installed
+// with InstallDownloaded. The installed files are hashed so later changes to
+// them can be detected.
+func InstalledBinaryFor(b models.Binaries) (models.InstalledBinary, error) {
+	t := TargetFor(b)
+	installed := models.InstalledBinary{
+		Name:            b.Name,
+		Version:         tagVersion(b, b.NewVersion),
+		Tag:             b.NewVersion,
+		Source:          b.URL,
+		DownloadURL:     b.DownloadURL,
+		Asset:           b.DownloadFileName,
+		OS:              t.OS,
+		Arch:            t.Arch,
+		InstallLocation: b.InstallLocation,
+		InstalledAt:     time.Now().UTC(),
+		Dirs:            b.InstalledDirs,
+		Store:           b.StoreDir,
+		Shell:           b.Shell,
+		Hooks:           b.Hooks,
+	}
+
+	if b.Sha.ShaType == "" || b.Sha.ShaType == "sha256" {
+		installed.SHA256 = strings.ToLower(b.Sha.Checksum)
+	}
+
+	if b.StoreDir != "" {
+		// Versioned binaries are known by their store directory, see binstall use
+		installed.Version = filepath.Base(b.StoreDir)
+	}
+
+	files, err := installedFiles(b.InstalledFiles)
+	if err != nil {
+		return models.InstalledBinary{}, err
+	}
+	installed.Files = files
+	return installed, nil
+}
+
+// installedFiles hashes the files at paths for the install state, symlinks
+// are recorded with their target
+func installedFiles(paths []string) ([]models.InstalledFile, error) {
+	var files []models.InstalledFile
+	for _, p := range paths {
+		info, err := os.Lstat(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat installed file %s: %w", p, err)
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(p)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read symlink %s: %w", p, err)
+			}
+			files = append(files, models.InstalledFile{Path: p, Symlink: target})
+			continue
+		}
+
+		checksum, err := utils.CalculateSHA256(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate sha256 for %s: %w", p, err)
+		}
+		files = append(files, models.InstalledFile{Path: p, SHA256: checksum})
+	}
+	return files, nil
 }
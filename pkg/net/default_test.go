@@ -20,6 +20,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg"
 	"github.com/akshaybabloo/binstall/pkg/utils"
 )
 
@@ -101,6 +102,30 @@ func TestResolveDownloadFileName(t *testing.T) {
 		assert.Equal(t, "", resolveDownloadFileName(b, "v1.0.0"))
 	})
 
+	t.Run("wildcard_os_and_arch_cover_every_platform", func(t *testing.T) {
+		b := models.Binaries{
+			Name: "tool",
+			Download: map[string]map[string]models.DownloadArchInfo{
+				"*": {"*": {FileName: "{{.Name}}_{{.VersionNoV}}_{{.OS}}_{{.ArchAlias}}.tar.gz"}},
+			},
+		}
+		want := fmt.Sprintf("tool_1.2.3_%s_%s.tar.gz", runtime.GOOS, utils.ArchAlias(runtime.GOARCH))
+		assert.Equal(t, want, resolveDownloadFileName(b, "v1.2.3"))
+	})
+
+	t.Run("exact_entry_wins_over_wildcard", func(t *testing.T) {
+		b := models.Binaries{
+			Download: map[string]map[string]models.DownloadArchInfo{
+				"*":          {"*": {FileName: "generic.tar.gz"}},
+				runtime.GOOS: {archKeyForCurrent(t): {FileName: "exact.tar.gz"}, "*": {FileName: "os-only.tar.gz"}},
+			},
+		}
+		assert.Equal(t, "exact.tar.gz", resolveDownloadFileName(b, "v1.2.3"))
+
+		delete(b.Download[runtime.GOOS], archKeyForCurrent(t))
+		assert.Equal(t, "os-only.tar.gz", resolveDownloadFileName(b, "v1.2.3"))
+	})
+
 	t.Run("aarch64_normalizes_to_arm64", func(t *testing.T) {
 		if runtime.GOARCH != "arm64" {
 			t.Skipf("skipping; needs runtime.GOARCH=arm64, got %s", runtime.GOARCH)
@@ -124,24 +149,156 @@ func TestResolveDownloadFileName(t *testing.T) {
 		}
 		assert.Equal(t, "tool-x86_64.tar.gz", resolveDownloadFileName(b, "v1.0.0"))
 	})
+
+	t.Run("exact_arm_variant_wins", func(t *testing.T) {
+		b := models.Binaries{
+			Download: map[string]map[string]models.DownloadArchInfo{
+				"linux": {
+					"arm":   {FileName: "tool-arm.tar.gz"},
+					"armv5": {FileName: "tool-armv5.tar.gz"},
+					"armv6": {FileName: "tool-armv6.tar.gz"},
+					"armv7": {FileName: "tool-armv7.tar.gz"},
+				},
+			},
+		}
+		for range 50 {
+			b.Target = models.OSArch{OS: "linux", Arch: "armv7"}
+			require.Equal(t, "tool-armv7.tar.gz", resolveDownloadFileName(b, "v1.0.0"))
+			b.Target = models.OSArch{OS: "linux", Arch: "armv6"}
+			require.Equal(t, "tool-armv6.tar.gz", resolveDownloadFileName(b, "v1.0.0"))
+		}
+	})
+
+	t.Run("closest_arm_variant_is_the_fallback", func(t *testing.T) {
+		b := models.Binaries{
+			Target: models.OSArch{OS: "linux", Arch: "armv6"},
+			Download: map[string]map[string]models.DownloadArchInfo{
+				"linux": {
+					"armv5": {FileName: "tool-armv5.tar.gz"},
+					"armv7": {FileName: "tool-armv7.tar.gz"},
+				},
+			},
+		}
+		assert.Equal(t, "tool-armv5.tar.gz", resolveDownloadFileName(b, "v1.0.0"), "armv7 doesn't run on armv6")
+
+		b.Download["linux"]["armv6hf"] = models.DownloadArchInfo{FileName: "tool-armv6hf.tar.gz"}
+		b.Target.Arch = "armv7"
+		assert.Equal(t, "tool-armv7.tar.gz", resolveDownloadFileName(b, "v1.0.0"))
+		delete(b.Download["linux"], "armv7")
+		assert.Equal(t, "tool-armv6hf.tar.gz", resolveDownloadFileName(b, "v1.0.0"))
+	})
 }
 
 func TestFindProvider(t *testing.T) {
 	tests := []struct {
-		name string
-		url  string
-		want int
+		name     string
+		url      string
+		provider models.Provider
+		want     models.Provider
 	}{
-		{"github_url", "https://github.com/owner/repo", GitHub},
-		{"gitlab_url", "https://gitlab.com/owner/repo", Others},
-		{"empty_url", "", Others},
+		{"github_url", "https://github.com/owner/repo", "", GitHub},
+		{"gitlab_url", "https://gitlab.com/owner/repo", "", GitLab},
+		{"self_hosted_gitlab_url", "https://gitlab.example.com/group/sub/repo", "", GitLab},
+		{"codeberg_url", "https://codeberg.org/owner/repo", "", Gitea},
+		{"self_hosted_forgejo_url", "https://forgejo.example.com/owner/repo", "", Gitea},
+		{"other_url", "https://dl.example.com/tool", "", Others},
+		{"empty_url", "", "", Others},
+		{"explicit_provider_wins", "https://git.example.com/owner/repo", "gitea", Gitea},
+		{"explicit_provider_is_case_insensitive", "https://git.example.com/owner/repo", "GitLab", GitLab},
+		{"forgejo_alias", "https://git.example.com/owner/repo", "forgejo", Gitea},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := findProvider(models.Binaries{URL: tt.url})
+			b, err := findProvider(models.Binaries{URL: tt.url, Provider: tt.provider})
+			require.NoError(t, err)
 			assert.Equal(t, tt.want, b.Provider)
 		})
 	}
+
+	t.Run("github_enterprise_host", func(t *testing.T) {
+		b, err := findProvider(models.Binaries{
+			URL:              "https://github.example.com/owner/repo",
+			GitHubEnterprise: models.GitHubEnterprise{BaseURL: "https://github.example.com/api/v3/"},
+		})
+		require.NoError(t, err)
+		assert.EqualValues(t, GitHub, b.Provider)
+	})
+
+	t.Run("unknown_provider", func(t *testing.T) {
+		_, err := findProvider(models.Binaries{Name: "tool", URL: "https://github.com/owner/repo", Provider: "gitub"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "use github, gitlab, gitea or others")
+	})
+}
+
+func TestGitHubEnterprise(t *testing.T) {
+	t.Run("client_targets_enterprise_api", func(t *testing.T) {
+		assetName := currentOSArchAssetName("tar.gz")
+		body, err := json.Marshal(&github.RepositoryRelease{
+			TagName: "v1.2.3",
+			Assets: []*github.ReleaseAsset{{
+				Name:               github.Ptr(assetName),
+				BrowserDownloadURL: github.Ptr("https://github.example.test/" + assetName),
+			}},
+		})
+		require.NoError(t, err)
+
+		var gotPath string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotPath = r.URL.Path
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(body)
+		}))
+		t.Cleanup(srv.Close)
+
+		b, err := findProvider(models.Binaries{
+			URL:              srv.URL + "/owner/repo",
+			GitHubEnterprise: models.GitHubEnterprise{BaseURL: srv.URL + "/"},
+		})
+		require.NoError(t, err)
+		got, err := checkForNewVersion(b)
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v3/repos/owner/repo/releases/latest", gotPath)
+		assert.Equal(t, "v1.2.3", got.NewVersion)
+	})
+
+	t.Run("github_com_ignores_enterprise_endpoints", func(t *testing.T) {
+		b := models.Binaries{
+			URL:              "https://github.com/owner/repo",
+			GitHubEnterprise: models.GitHubEnterprise{BaseURL: "https://github.example.com/"},
+		}
+		assert.Equal(t, models.GitHubEnterprise{}, gitHubEnterpriseFor(b))
+
+		c, err := newGitHubClient("", gitHubEnterpriseFor(b))
+		require.NoError(t, err)
+		assert.Equal(t, "https://api.github.com/", c.BaseURL())
+	})
+
+	t.Run("flags_env_and_config", func(t *testing.T) {
+		t.Setenv("GITHUB_ENTERPRISE_URL", "https://env.example.com/")
+		t.Setenv("GITHUB_ENTERPRISE_UPLOAD_URL", "https://uploads.env.example.com/")
+
+		b := WithGitHubEnterprise(models.Binaries{}, "https://flag.example.com/", "")
+		assert.Equal(t, models.GitHubEnterprise{BaseURL: "https://flag.example.com/", UploadURL: "https://uploads.env.example.com/"}, b.GitHubEnterprise)
+
+		b = WithGitHubEnterprise(models.Binaries{}, "", "")
+		assert.Equal(t, models.GitHubEnterprise{BaseURL: "https://env.example.com/", UploadURL: "https://uploads.env.example.com/"}, b.GitHubEnterprise)
+
+		own := models.GitHubEnterprise{BaseURL: "https://own.example.com/"}
+		b = WithGitHubEnterprise(models.Binaries{GitHubEnterprise: own}, "https://flag.example.com/", "")
+		assert.Equal(t, own, b.GitHubEnterprise, "the binary config wins")
+
+		t.Setenv("GITHUB_ENTERPRISE_URL", "")
+		b = WithGitHubEnterprise(models.Binaries{}, "", "")
+		assert.Equal(t, models.GitHubEnterprise{}, b.GitHubEnterprise)
+	})
+
+	t.Run("upload_url_defaults_to_base_url", func(t *testing.T) {
+		c, err := newGitHubClient("", models.GitHubEnterprise{BaseURL: "https://github.example.com/"})
+		require.NoError(t, err)
+		assert.Equal(t, "https://github.example.com/api/v3/", c.BaseURL())
+		assert.Equal(t, "https://github.example.com/api/uploads/", c.UploadURL())
+	})
 }
 
 // writeFileWithSHA writes content to a temp file and returns its path and SHA-256 hex.
@@ -370,6 +527,28 @@ func TestMoveFiles(t *testing.T) {
 		assert.True(t, os.IsNotExist(err), "original name should not exist at install location")
 	})
 
+	t.Run("foreign_target_skips_version_check", func(t *testing.T) {
+		downloadDir := t.TempDir()
+		installDir := t.TempDir()
+		// Not executable on the host, running it would fail
+		require.NoError(t, os.WriteFile(filepath.Join(downloadDir, "tool"), []byte("\x7fELF not for this host"), 0o644))
+
+		b := models.Binaries{
+			Name:            "test",
+			DownloadFolder:  downloadDir,
+			InstallLocation: installDir,
+			Target:          models.OSArch{OS: otherOS(), Arch: "arm64"},
+			Files: []models.File{{
+				FileName:       "tool",
+				CopyIt:         true,
+				CheckVersion:   true,
+				VersionCommand: models.VersionCommand{Args: "--version"},
+			}},
+		}
+		require.NoError(t, moveFiles(&b))
+		assert.FileExists(t, filepath.Join(installDir, "tool"))
+	})
+
 	t.Run("source_path_used", func(t *testing.T) {
 		downloadDir := t.TempDir()
 		installDir := t.TempDir()
@@ -595,6 +774,21 @@ func TestMoveFiles(t *testing.T) {
 		target, err = os.Readlink(filepath.Join(installDir, "libfoo.so"))
 		require.NoError(t, err)
 		assert.Equal(t, "libfoo.so.0", target)
+
+		// Symlinks are recorded as installed files too
+		assert.ElementsMatch(t, []string{
+			filepath.Join(installDir, "libfoo.so"),
+			filepath.Join(installDir, "libfoo.so.0"),
+			filepath.Join(installDir, "libfoo.so.1.2.3"),
+		}, b.InstalledFiles)
+		entry, err := InstalledBinaryFor(b)
+		require.NoError(t, err)
+		for _, f := range entry.Files {
+			if f.Path == filepath.Join(installDir, "libfoo.so") {
+				assert.Equal(t, "libfoo.so.0", f.Symlink)
+				assert.Empty(t, f.SHA256)
+			}
+		}
 	})
 
 	t.Run("wildcard_copy_contents_from_template", func(t *testing.T) {
@@ -732,13 +926,65 @@ func withGitHubServer(t *testing.T, tagName string, assets []*github.ReleaseAsse
 	t.Cleanup(srv.Close)
 
 	old := newGitHubClient
-	newGitHubClient = func(token string) (*github.Client, error) {
+	newGitHubClient = func(token string, _ models.GitHubEnterprise) (*github.Client, error) {
+		return github.NewClient(github.WithEnterpriseURLs(srv.URL+"/", srv.URL+"/"))
+	}
+	t.Cleanup(func() { newGitHubClient = old })
+	return srv
+}
+
+// otherOS returns an OS binstall isn't running on, for cross-target tests
+func otherOS() string {
+	if runtime.GOOS == "linux" {
+		return "darwin"
+	}
+	return "linux"
+}
+
+// withGitHubReleasesServer is like withGitHubServer but serves the release
+// list as well, with the first release doubling as the latest release.
+func withGitHubReleasesServer(t *testing.T, releases []*github.RepositoryRelease) *httptest.Server {
+	t.Helper()
+	list, err := json.Marshal(releases)
+	require.NoError(t, err)
+	latest, err := json.Marshal(releases[0])
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/releases/latest"):
+			_, _ = w.Write(latest)
+		case strings.HasSuffix(r.URL.Path, "/releases"):
+			_, _ = w.Write(list)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	old := newGitHubClient
+	newGitHubClient = func(token string, _ models.GitHubEnterprise) (*github.Client, error) {
 		return github.NewClient(github.WithEnterpriseURLs(srv.URL+"/", srv.URL+"/"))
 	}
 	t.Cleanup(func() { newGitHubClient = old })
 	return srv
 }
 
+// gitHubReleaseWithAsset builds a release carrying one asset for the host OS/arch
+func gitHubReleaseWithAsset(tagName string, prerelease bool) *github.RepositoryRelease {
+	assetName := currentOSArchAssetName("tar.gz")
+	return &github.RepositoryRelease{
+		TagName:    tagName,
+		Prerelease: prerelease,
+		Assets: []*github.ReleaseAsset{{
+			Name:               github.Ptr(assetName),
+			BrowserDownloadURL: github.Ptr("https://example.test/" + tagName + "/" + assetName),
+			ContentType:        github.Ptr("application/gzip"),
+		}},
+	}
+}
+
 // currentOSArchAssetName builds a release asset name that FigureOutOSAndArch
 // will resolve to the host's runtime.GOOS / runtime.GOARCH.
 func currentOSArchAssetName(suffix string) string {
@@ -816,6 +1062,20 @@ func TestGetCurrentVersion(t *testing.T) {
 // ---------------------------------------------------------------------------
 
 func TestVerifyNewBin(t *testing.T) {
+	t.Run("other_target_is_not_executed", func(t *testing.T) {
+		b := models.Binaries{
+			InstallLocation: t.TempDir(), // empty, so a check would fail
+			NewVersion:      "1.2.3",
+			Target:          models.OSArch{OS: otherOS(), Arch: "arm64"},
+			Files: []models.File{{
+				FileName:       "mytool",
+				CheckVersion:   true,
+				VersionCommand: models.VersionCommand{Args: "--version", RegexVersion: `\d+\.\d+\.\d+`},
+			}},
+		}
+		assert.NoError(t, verifyNewBin(b))
+	})
+
 	t.Run("happy_path", func(t *testing.T) {
 		installDir := t.TempDir()
 		writeShellScript(t, installDir, "mytool", `echo "1.2.3"`)
@@ -870,6 +1130,24 @@ func TestVerifyNewBin(t *testing.T) {
 		assert.Contains(t, err.Error(), "version mismatch")
 	})
 
+	t.Run("rolling_nightly_only_needs_to_execute", func(t *testing.T) {
+		installDir := t.TempDir()
+		writeShellScript(t, installDir, "mytool", `echo "1.3.0-dev"`)
+		t.Setenv("PATH", installDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		b := models.Binaries{
+			InstallLocation: installDir,
+			NewVersion:      "nightly",
+			Channel:         ChannelNightly,
+			Files: []models.File{{
+				FileName:       "mytool",
+				CheckVersion:   true,
+				VersionCommand: models.VersionCommand{Args: "--version", RegexVersion: `\d+\.\d+\.\d+`},
+			}},
+		}
+		assert.NoError(t, verifyNewBin(b))
+	})
+
 	t.Run("no_checkVersion_file_skips", func(t *testing.T) {
 		b := models.Binaries{
 			InstallLocation: t.TempDir(),
@@ -907,6 +1185,71 @@ func TestDownloadFile(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, body, string(data))
 	})
+
+	t.Run("http_status", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "API rate limit exceeded", http.StatusForbidden)
+		}))
+		t.Cleanup(srv.Close)
+
+		b := models.Binaries{
+			Name:             uniqueTempName(t),
+			DownloadFileName: "tool.tar.gz",
+			DownloadURL:      srv.URL + "/tool.tar.gz",
+		}
+		_, err := downloadFile(b)
+		require.ErrorIs(t, err, pkg.ErrDownloadHTTPStatus)
+
+		var statusErr *pkg.DownloadStatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusForbidden, statusErr.StatusCode)
+		assert.Equal(t, "API rate limit exceeded", statusErr.Body)
+		assert.NoFileExists(t, filepath.Join(os.TempDir(), b.Name, "tool.tar.gz"), "error pages are not saved as the asset")
+	})
+
+	t.Run("html_page", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte("<html>sign in</html>"))
+		}))
+		t.Cleanup(srv.Close)
+
+		b := models.Binaries{
+			Name:             uniqueTempName(t),
+			DownloadFileName: "tool.tar.gz",
+			DownloadURL:      srv.URL + "/tool.tar.gz",
+		}
+		_, err := downloadFile(b)
+		require.ErrorIs(t, err, pkg.ErrDownloadUnexpectedContent)
+	})
+
+	t.Run("size_mismatch", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("short"))
+		}))
+		t.Cleanup(srv.Close)
+
+		b := models.Binaries{
+			Name:             uniqueTempName(t),
+			DownloadFileName: "tool.tar.gz",
+			DownloadURL:      srv.URL + "/tool.tar.gz",
+			DownloadSize:     1024,
+		}
+		_, err := downloadFile(b)
+		require.ErrorIs(t, err, pkg.ErrDownloadSizeMismatch)
+		assert.NoFileExists(t, filepath.Join(os.TempDir(), b.Name, "tool.tar.gz"))
+	})
+}
+
+func TestCheckDownloadSize(t *testing.T) {
+	b := models.Binaries{Name: "tool"}
+	require.NoError(t, checkDownloadSize(b, 10, 10))
+	require.NoError(t, checkDownloadSize(b, -1, 10), "unknown Content-Length")
+	require.ErrorIs(t, checkDownloadSize(b, 100, 10), pkg.ErrDownloadSizeMismatch)
+
+	b.DownloadSize = 10
+	require.NoError(t, checkDownloadSize(b, -1, 10))
+	require.ErrorIs(t, checkDownloadSize(b, -1, 9), pkg.ErrDownloadSizeMismatch)
 }
 
 // ---------------------------------------------------------------------------
@@ -1062,7 +1405,7 @@ func TestCheckForNewVersion(t *testing.T) {
 	})
 
 	t.Run("ignores_disallowed_extensions", func(t *testing.T) {
-		// The .deb asset matches OS/arch but should be skipped via ignoreFileExt.
+		// The .deb asset matches OS/arch but is rejected as a system package.
 		debName := fmt.Sprintf("tool-%s-%s.deb", runtime.GOOS, runtime.GOARCH)
 		gzName := currentOSArchAssetName("tar.gz")
 		gzURL := "https://example.test/" + gzName
@@ -1092,9 +1435,358 @@ func TestCheckForNewVersion(t *testing.T) {
 	})
 
 	t.Run("non_github_provider_returns_ErrNetBinaryNotFound", func(t *testing.T) {
-		b := models.Binaries{URL: "https://gitlab.com/owner/repo", Provider: Others}
+		b := models.Binaries{URL: "https://dl.example.com/tool", Provider: Others}
+		_, err := checkForNewVersion(b)
+		require.Error(t, err)
+	})
+
+	t.Run("unknown_provider_returns_error", func(t *testing.T) {
+		b := models.Binaries{Name: "tool", URL: "https://dl.example.com/tool", Provider: "sourceforge"}
+		_, err := checkForNewVersion(b)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported provider")
+	})
+}
+
+func TestSelectAsset_Patterns(t *testing.T) {
+	assets := func(names ...string) release {
+		rel := release{TagName: "v1.2.3"}
+		for _, name := range names {
+			rel.Assets = append(rel.Assets, releaseAsset{Name: name, URL: "https://example.test/" + name})
+		}
+		return rel
+	}
+	gnu := fmt.Sprintf("tool-%s-%s-gnu.tar.gz", runtime.GOOS, runtime.GOARCH)
+	musl := fmt.Sprintf("tool-%s-%s-musl.tar.gz", runtime.GOOS, runtime.GOARCH)
+	sum := fmt.Sprintf("tool-%s-%s-gnu.tar.gz.sha256", runtime.GOOS, runtime.GOARCH)
+
+	t.Run("glob_pattern", func(t *testing.T) {
+		b := models.Binaries{AssetPattern: "tool-{{.OS}}-{{.Arch}}-musl.*"}
+		got, err := selectAsset(b, assets(gnu, musl))
+		require.NoError(t, err)
+		assert.Equal(t, musl, got.DownloadFileName)
+	})
+
+	t.Run("regex_pattern", func(t *testing.T) {
+		b := models.Binaries{AssetPattern: `/-musl\.tar\.gz$/`}
+		got, err := selectAsset(b, assets(gnu, musl))
+		require.NoError(t, err)
+		assert.Equal(t, musl, got.DownloadFileName)
+	})
+
+	t.Run("exclude_applies_to_pattern", func(t *testing.T) {
+		b := models.Binaries{AssetPattern: "tool-*", AssetExclude: []string{"*.sha256", "*-musl*"}}
+		got, err := selectAsset(b, assets(sum, musl, gnu))
+		require.NoError(t, err)
+		assert.Equal(t, gnu, got.DownloadFileName)
+	})
+
+	t.Run("exclude_applies_to_heuristic", func(t *testing.T) {
+		b := models.Binaries{AssetExclude: []string{"*-gnu*"}}
+		got, err := selectAsset(b, assets(gnu, musl))
+		require.NoError(t, err)
+		assert.Equal(t, musl, got.DownloadFileName)
+	})
+
+	t.Run("ambiguous_pattern_lists_assets", func(t *testing.T) {
+		b := models.Binaries{Name: "tool", AssetPattern: "tool-*.tar.gz"}
+		_, err := selectAsset(b, assets(gnu, musl))
+		require.ErrorIs(t, err, pkg.ErrNetAmbiguousAsset)
+		assert.Contains(t, err.Error(), gnu)
+		assert.Contains(t, err.Error(), musl)
+	})
+
+	t.Run("no_match_falls_back_to_heuristic", func(t *testing.T) {
+		b := models.Binaries{AssetPattern: "does-not-exist-*"}
+		got, err := selectAsset(b, assets(gnu))
+		require.NoError(t, err)
+		assert.Equal(t, gnu, got.DownloadFileName)
+	})
+
+	t.Run("invalid_pattern_returns_error", func(t *testing.T) {
+		b := models.Binaries{AssetPattern: "/[/"}
+		_, err := selectAsset(b, assets(gnu))
+		require.Error(t, err)
+	})
+}
+
+// ---------------------------------------------------------------------------
+// pickRelease / pinned versions
+// ---------------------------------------------------------------------------
+
+func TestPickRelease(t *testing.T) {
+	releases := []release{
+		{TagName: "v3.0.0-rc1", Prerelease: true},
+		{TagName: "v2.1.0"},
+		{TagName: "v2.0.0"},
+		{TagName: "v1.5.0"},
+		{TagName: "v1.4.9"},
+		{TagName: "v1.4.2"},
+		{TagName: "nightly"},
+	}
+	tests := []struct {
+		name    string
+		want    string
+		tag     string
+		wantErr bool
+	}{
+		{name: "exact_tag", want: "v1.4.2", tag: "v1.4.2"},
+		{name: "exact_tag_without_v", want: "1.4.2", tag: "v1.4.2"},
+		{name: "non_semver_tag", want: "nightly", tag: "nightly"},
+		{name: "pessimistic", want: "~> 1.4", tag: "v1.5.0"},
+		{name: "pessimistic_patch", want: "~> 1.4.0", tag: "v1.4.9"},
+		{name: "range", want: ">=2.0,<3", tag: "v2.1.0"},
+		{name: "skips_prereleases", want: ">= 2.0", tag: "v2.1.0"},
+		{name: "no_match", want: ">= 4.0", wantErr: true},
+		{name: "unknown_tag", want: "not-a-tag", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pickRelease(releases, tt.want, false)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.tag, got.TagName)
+		})
+	}
+}
+
+func TestCheckForNewVersion_PinnedVersion(t *testing.T) {
+	t.Run("constraint_picks_highest_matching_release", func(t *testing.T) {
+		withGitHubReleasesServer(t, []*github.RepositoryRelease{
+			gitHubReleaseWithAsset("v2.0.0", false),
+			gitHubReleaseWithAsset("v1.5.1", false),
+			gitHubReleaseWithAsset("v1.4.0", false),
+		})
+
+		b := models.Binaries{URL: "https://github.com/owner/repo", Provider: GitHub, Version: "~> 1.4"}
+		got, err := checkForNewVersion(b)
+		require.NoError(t, err)
+		assert.Equal(t, "v1.5.1", got.NewVersion)
+		assert.Contains(t, got.DownloadURL, "/v1.5.1/")
+	})
+
+	t.Run("no_matching_release_returns_error", func(t *testing.T) {
+		withGitHubReleasesServer(t, []*github.RepositoryRelease{gitHubReleaseWithAsset("v2.0.0", false)})
+
+		b := models.Binaries{URL: "https://github.com/owner/repo", Provider: GitHub, Version: "< 1.0"}
 		_, err := checkForNewVersion(b)
 		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no release matches")
+	})
+}
+
+func TestResolveRelease_Channels(t *testing.T) {
+	releases := []*github.RepositoryRelease{
+		gitHubReleaseWithAsset("nightly", true),
+		gitHubReleaseWithAsset("v2.0.0-rc.1", true),
+		gitHubReleaseWithAsset("v1.9.0", false),
+		gitHubReleaseWithAsset("v1.10.0-beta.1", true),
+	}
+
+	tests := []struct {
+		name    string
+		channel string
+		version string
+		want    string
+		wantErr bool
+	}{
+		{name: "stable_uses_latest_release", channel: "", want: "nightly"},
+		{name: "prerelease_picks_highest_version", channel: "prerelease", want: "v2.0.0-rc.1"},
+		{name: "prerelease_with_constraint", channel: "Prerelease", version: "~> 1.9", want: "v1.10.0-beta.1"},
+		{name: "stable_with_constraint_skips_prereleases", channel: "stable", version: "~> 1.9", want: "v1.9.0"},
+		{name: "nightly_tag", channel: "nightly", want: "nightly"},
+		{name: "unknown_channel", channel: "weekly", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The fake latest endpoint serves the first release; real GitHub
+			// would skip prereleases, which is why the other channels list releases.
+			withGitHubReleasesServer(t, releases)
+
+			b := models.Binaries{URL: "https://github.com/owner/repo", Provider: GitHub, Channel: tt.channel, Version: tt.version}
+			got, err := resolveRelease(b, "")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.TagName)
+		})
+	}
+}
+
+func TestCheckUpdates_Channels(t *testing.T) {
+	versionFile := func(name string) []models.File {
+		return []models.File{{
+			FileName:       name,
+			CheckVersion:   true,
+			VersionCommand: models.VersionCommand{Args: "--version", RegexVersion: `\d+\.\d+\.\d+(-[0-9A-Za-z.]+)?`},
+		}}
+	}
+
+	t.Run("prerelease_newer_than_installed_stable", func(t *testing.T) {
+		bindir := t.TempDir()
+		writeShellScript(t, bindir, "mytool", `echo "mytool 1.9.0"`)
+		t.Setenv("PATH", bindir+string(os.PathListSeparator)+os.Getenv("PATH"))
+		withGitHubReleasesServer(t, []*github.RepositoryRelease{
+			gitHubReleaseWithAsset("v2.0.0-rc.1", true),
+			gitHubReleaseWithAsset("v1.9.0", false),
+		})
+
+		b := models.Binaries{URL: "https://github.com/owner/repo", Channel: ChannelPrerelease, Files: versionFile("mytool")}
+		got, err := CheckUpdates(b)
+		require.NoError(t, err)
+		assert.True(t, got.UpdatesAvailable)
+		assert.Equal(t, "v2.0.0-rc.1", got.NewVersion)
+	})
+
+	t.Run("installed_stable_is_newer_than_its_prerelease", func(t *testing.T) {
+		bindir := t.TempDir()
+		writeShellScript(t, bindir, "mytool", `echo "mytool 2.0.0"`)
+		t.Setenv("PATH", bindir+string(os.PathListSeparator)+os.Getenv("PATH"))
+		// The stable 2.0.0 isn't published as a release here, only its rc.
+		withGitHubReleasesServer(t, []*github.RepositoryRelease{
+			gitHubReleaseWithAsset("v2.0.0-rc.2", true),
+		})
+
+		b := models.Binaries{URL: "https://github.com/owner/repo", Channel: ChannelPrerelease, Files: versionFile("mytool")}
+		got, err := CheckUpdates(b)
+		require.NoError(t, err)
+		assert.False(t, got.UpdatesAvailable)
+	})
+
+	t.Run("rolling_nightly_is_always_reinstalled", func(t *testing.T) {
+		bindir := t.TempDir()
+		writeShellScript(t, bindir, "mytool", `echo "mytool 2.0.0"`)
+		t.Setenv("PATH", bindir+string(os.PathListSeparator)+os.Getenv("PATH"))
+		withGitHubReleasesServer(t, []*github.RepositoryRelease{
+			gitHubReleaseWithAsset("nightly", true),
+			gitHubReleaseWithAsset("v2.0.0", false),
+		})
+
+		b := models.Binaries{URL: "https://github.com/owner/repo", Channel: ChannelNightly, Files: versionFile("mytool")}
+		got, err := CheckUpdates(b)
+		require.NoError(t, err)
+		assert.True(t, got.UpdatesAvailable)
+		assert.Equal(t, "nightly", got.NewVersion)
+	})
+}
+
+func TestResolveRelease_TagPrefix(t *testing.T) {
+	withGitHubReleasesServer(t, []*github.RepositoryRelease{
+		gitHubReleaseWithAsset("sdk/v4.0.0", false),
+		gitHubReleaseWithAsset("cli/v1.3.0-rc.1", true),
+		gitHubReleaseWithAsset("cli/v1.2.3", false),
+		gitHubReleaseWithAsset("cli/v1.10.0", false),
+	})
+
+	t.Run("latest_of_component", func(t *testing.T) {
+		b := models.Binaries{URL: "https://github.com/owner/repo", Provider: GitHub, TagPrefix: "cli/"}
+		got, err := resolveRelease(b, "")
+		require.NoError(t, err)
+		assert.Equal(t, "cli/v1.10.0", got.TagName)
+		assert.Equal(t, "v1.10.0", got.StrippedTag)
+	})
+
+	t.Run("pattern_with_constraint", func(t *testing.T) {
+		b := models.Binaries{URL: "https://github.com/owner/repo", Provider: GitHub, TagPattern: `^cli/(v.+)$`, Version: "~> 1.2.0"}
+		got, err := resolveRelease(b, "")
+		require.NoError(t, err)
+		assert.Equal(t, "cli/v1.2.3", got.TagName)
+	})
+
+	t.Run("no_matching_component", func(t *testing.T) {
+		b := models.Binaries{URL: "https://github.com/owner/repo", Provider: GitHub, TagPrefix: "docs/"}
+		_, err := resolveRelease(b, "")
+		require.Error(t, err)
+	})
+
+	t.Run("download_template_sees_full_and_stripped_tag", func(t *testing.T) {
+		b := models.Binaries{
+			TagPrefix: "cli/",
+			Download: map[string]map[string]models.DownloadArchInfo{
+				runtime.GOOS: {archKeyForCurrent(t): {FileName: "cli-{{.StrippedVersion}}.tar.gz#{{.Version}}"}},
+			},
+		}
+		assert.Equal(t, "cli-v1.2.3.tar.gz#cli/v1.2.3", resolveDownloadFileName(b, "cli/v1.2.3"))
+	})
+}
+
+func TestCheckUpdates_TagPrefix(t *testing.T) {
+	bindir := t.TempDir()
+	writeShellScript(t, bindir, "mytool", `echo "mytool 1.2.3"`)
+	t.Setenv("PATH", bindir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	withGitHubReleasesServer(t, []*github.RepositoryRelease{
+		gitHubReleaseWithAsset("sdk/v4.0.0", false),
+		gitHubReleaseWithAsset("cli/v1.10.0", false),
+	})
+
+	b := models.Binaries{
+		URL:       "https://github.com/owner/repo",
+		TagPrefix: "cli/",
+		Files: []models.File{{
+			FileName:       "mytool",
+			CheckVersion:   true,
+			VersionCommand: models.VersionCommand{Args: "--version", RegexVersion: `\d+\.\d+\.\d+`},
+		}},
+	}
+	got, err := CheckUpdates(b)
+	require.NoError(t, err)
+	assert.True(t, got.UpdatesAvailable)
+	assert.Equal(t, "cli/v1.10.0", got.NewVersion)
+}
+
+func TestCheckUpdates_PinnedVersion(t *testing.T) {
+	t.Run("pinned_older_version_is_installed", func(t *testing.T) {
+		bindir := t.TempDir()
+		writeShellScript(t, bindir, "mytool", `echo "mytool 2.0.0"`)
+		t.Setenv("PATH", bindir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		withGitHubReleasesServer(t, []*github.RepositoryRelease{
+			gitHubReleaseWithAsset("v2.0.0", false),
+			gitHubReleaseWithAsset("v1.4.2", false),
+		})
+
+		b := models.Binaries{
+			URL:     "https://github.com/owner/repo",
+			Version: "v1.4.2",
+			Files: []models.File{{
+				FileName:       "mytool",
+				CheckVersion:   true,
+				VersionCommand: models.VersionCommand{Args: "--version", RegexVersion: `\d+\.\d+\.\d+`},
+			}},
+		}
+		got, err := CheckUpdates(b)
+		require.NoError(t, err)
+		assert.True(t, got.UpdatesAvailable)
+		assert.Equal(t, "v1.4.2", got.NewVersion)
+	})
+
+	t.Run("pinned_version_already_installed", func(t *testing.T) {
+		bindir := t.TempDir()
+		writeShellScript(t, bindir, "mytool", `echo "mytool 1.4.2"`)
+		t.Setenv("PATH", bindir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		withGitHubReleasesServer(t, []*github.RepositoryRelease{
+			gitHubReleaseWithAsset("v2.0.0", false),
+			gitHubReleaseWithAsset("v1.4.2", false),
+		})
+
+		b := models.Binaries{
+			URL:     "https://github.com/owner/repo",
+			Version: "~> 1.4",
+			Files: []models.File{{
+				FileName:       "mytool",
+				CheckVersion:   true,
+				VersionCommand: models.VersionCommand{Args: "--version", RegexVersion: `\d+\.\d+\.\d+`},
+			}},
+		}
+		got, err := CheckUpdates(b)
+		require.NoError(t, err)
+		assert.False(t, got.UpdatesAvailable)
 	})
 }
 
@@ -1102,6 +1794,48 @@ func TestCheckForNewVersion(t *testing.T) {
 // CheckUpdates
 // ---------------------------------------------------------------------------
 
+func TestCheckUpdates_Target(t *testing.T) {
+	target := models.OSArch{OS: otherOS(), Arch: "armv7"}
+	targetAsset := fmt.Sprintf("tool_%s_armv7.tar.gz", target.OS)
+	withGitHubServer(t, "v1.2.3", []*github.ReleaseAsset{
+		{Name: github.Ptr(currentOSArchAssetName("tar.gz")), BrowserDownloadURL: github.Ptr("https://example.test/host.tar.gz")},
+		{Name: github.Ptr(fmt.Sprintf("tool_%s_armv6.tar.gz", target.OS)), BrowserDownloadURL: github.Ptr("https://example.test/armv6.tar.gz")},
+		{Name: github.Ptr(targetAsset), BrowserDownloadURL: github.Ptr("https://example.test/armv7.tar.gz")},
+	})
+
+	files := []models.File{{
+		FileName:       "binstall-test-tool-that-does-not-exist",
+		CheckVersion:   true,
+		VersionCommand: models.VersionCommand{Args: "--version", RegexVersion: `\d+\.\d+\.\d+`},
+	}}
+
+	t.Run("asset_heuristic", func(t *testing.T) {
+		b := models.Binaries{Name: "tool", URL: "https://github.com/owner/repo", Files: files, Target: target}
+		got, err := CheckUpdates(b)
+		require.NoError(t, err)
+		assert.True(t, got.UpdatesAvailable)
+		assert.Equal(t, "Not Checked", got.CurrentVersion)
+		assert.Equal(t, targetAsset, got.DownloadFileName)
+		assert.Equal(t, models.OSArch{OS: target.OS, Arch: "arm", Variant: "7"}, got.OsInfo)
+	})
+
+	t.Run("download_template", func(t *testing.T) {
+		b := models.Binaries{
+			Name:   "tool",
+			URL:    "https://github.com/owner/repo",
+			Files:  files,
+			Target: target,
+			Download: map[string]map[string]models.DownloadArchInfo{
+				target.OS: {"arm": {FileName: "tool_{{.OS}}_armv7.tar.gz"}},
+			},
+		}
+		got, err := CheckUpdates(b)
+		require.NoError(t, err)
+		assert.Equal(t, targetAsset, got.DownloadFileName)
+		assert.Equal(t, target.OS, got.OsInfo.OS)
+	})
+}
+
 func TestCheckUpdates(t *testing.T) {
 	t.Run("update_available", func(t *testing.T) {
 		// Local binary reports 1.0.0; release reports v1.2.3.
@@ -1318,9 +2052,9 @@ func TestDownloadAndMoveFiles(t *testing.T) {
 	})
 
 	t.Run("download_error_propagates", func(t *testing.T) {
-		// resty against an unreachable port: SetOutput still writes a (likely
-		// zero-byte) file, so this exercises the URL-error path of resty/Get.
-		// Use a closed listener URL.
+		// Use a closed listener URL, so this exercises the URL-error path of
+		// resty/Get. Retries are disabled to keep the test fast.
+		withRetry(t, RetryPolicy{})
 		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
 		srv.Close() // immediately close so the URL is unreachable
 
@@ -1334,3 +2068,151 @@ func TestDownloadAndMoveFiles(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestDownloadBinaryAndInstallDownloaded(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("end-to-end install relies on POSIX shell scripts")
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "release.tar.gz")
+	makeTarGz(t, archivePath, map[string]string{
+		"tool": "#!/bin/sh\necho \"1.2.3\"",
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, archivePath)
+	}))
+	t.Cleanup(srv.Close)
+
+	installDir := t.TempDir()
+	t.Setenv("PATH", installDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	b := models.Binaries{
+		Name:             uniqueTempName(t),
+		NewVersion:       "1.2.3",
+		DownloadURL:      srv.URL + "/release.tar.gz",
+		DownloadFileName: "release.tar.gz",
+		ContentType:      "application/gzip",
+		InstallLocation:  installDir,
+		Files: []models.File{{
+			FileName:       "tool",
+			CopyIt:         true,
+			CheckVersion:   true,
+			VersionCommand: models.VersionCommand{Args: "--version", RegexVersion: `\d+\.\d+\.\d+`},
+		}},
+	}
+
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dl, err := DownloadBinary(b)
+	require.NoError(t, err)
+	want, err := utils.CalculateSHA256(archivePath)
+	require.NoError(t, err)
+	assert.Equal(t, want, dl.Sha.Checksum)
+	assert.NoFileExists(t, filepath.Join(installDir, "tool"), "DownloadBinary must not install")
+
+	t.Run("checksum_mismatch", func(t *testing.T) {
+		bad := dl
+		bad.Sha.Checksum = strings.Repeat("0", 64)
+		_, err := InstallDownloaded(bad)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "checksum mismatch")
+	})
+
+	t.Run("installs_without_network", func(t *testing.T) {
+		srv.Close()
+		installed, err := InstallDownloaded(dl)
+		require.NoError(t, err)
+		assert.FileExists(t, filepath.Join(installDir, "tool"))
+		assert.Equal(t, []string{filepath.Join(installDir, "tool")}, installed.InstalledFiles)
+
+		entry, err := InstalledBinaryFor(installed)
+		require.NoError(t, err)
+		assert.Equal(t, "1.2.3", entry.Version)
+		assert.Equal(t, installDir, entry.InstallLocation)
+		require.Len(t, entry.Files, 1)
+		want, err := utils.CalculateSHA256(filepath.Join(installDir, "tool"))
+		require.NoError(t, err)
+		assert.Equal(t, want, entry.Files[0].SHA256)
+	})
+}
+
+func TestCheckLockedUpdates(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on POSIX shell scripts")
+	}
+
+	entry := models.LockEntry{
+		Name:   "mytool",
+		OS:     runtime.GOOS,
+		Arch:   runtime.GOARCH,
+		Tag:    "v1.2.0",
+		URL:    "https://example.test/" + currentOSArchAssetName("tar.gz"),
+		Asset:  currentOSArchAssetName("tar.gz"),
+		SHA256: strings.Repeat("a", 64),
+	}
+	files := []models.File{{
+		FileName:       "mytool",
+		CheckVersion:   true,
+		VersionCommand: models.VersionCommand{Args: "--version", RegexVersion: `\d+\.\d+\.\d+`},
+	}}
+
+	tests := []struct {
+		name      string
+		installed string
+		want      bool
+	}{
+		{name: "same_version_is_kept", installed: "1.2.0", want: false},
+		{name: "newer_installed_is_downgraded", installed: "1.3.0", want: true},
+		{name: "older_installed_is_updated", installed: "1.1.0", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installDir := t.TempDir()
+			writeShellScript(t, installDir, "mytool", fmt.Sprintf("echo %q", tt.installed))
+			t.Setenv("PATH", installDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+			// No provider server is running, so anything but the lock entry would fail
+			b := models.Binaries{Name: "mytool", URL: "https://github.com/owner/repo", Files: files}
+			got, err := CheckLockedUpdates(b, entry)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.UpdatesAvailable)
+			assert.Equal(t, entry.Tag, got.NewVersion)
+			assert.Equal(t, entry.URL, got.DownloadURL)
+			assert.Equal(t, entry.SHA256, got.Sha.Checksum)
+		})
+	}
+
+	t.Run("checksum_mismatch_fails_the_download", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("changed upstream"))
+		}))
+		t.Cleanup(srv.Close)
+
+		locked := entry
+		locked.URL = srv.URL + "/" + entry.Asset
+		b := lockedBinary(models.Binaries{Name: uniqueTempName(t)}, locked)
+		t.Setenv("XDG_CACHE_HOME", t.TempDir())
+		_, err := DownloadBinary(b)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "checksum mismatch")
+	})
+
+	t.Run("platform_comes_from_the_entry", func(t *testing.T) {
+		locked := models.LockEntry{
+			Name:  "mytool",
+			OS:    "linux",
+			Arch:  "arm64",
+			Tag:   "v1.2.0",
+			URL:   "https://example.test/mytool-universal-musl.tar.gz",
+			Asset: "mytool-universal-musl.tar.gz",
+		}
+		b := lockedBinary(models.Binaries{Name: "mytool"}, locked)
+		assert.Equal(t, "linux", b.OsInfo.OS)
+		assert.Equal(t, "arm64", b.OsInfo.Arch)
+		assert.Equal(t, utils.LibcMusl, b.OsInfo.Libc)
+	})
+
+	t.Run("lock_entry_round_trip", func(t *testing.T) {
+		b := lockedBinary(models.Binaries{Name: "mytool"}, entry)
+		assert.Equal(t, entry, LockEntryFor(b))
+	})
+}
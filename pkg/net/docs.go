@@ -0,0 +1,139 @@
+package net
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg/fileio"
+)
+
+// completionPath returns where the shell completion of the binary name is
+// installed in dataHome. These are the user directories bash-completion,
+// zsh (once added to fpath) and fish load completions from.
+func completionPath(dataHome, shell, name string) (string, error) {
+	switch strings.ToLower(shell) {
+	case "bash":
+		return filepath.Join(dataHome, "bash-completion", "completions", name), nil
+	case "zsh":
+		return filepath.Join(dataHome, "zsh", "site-functions", "_"+name), nil
+	case "fish":
+		return filepath.Join(dataHome, "fish", "vendor_completions.d", name+".fish"), nil
+	default:
+		return "", fmt.Errorf("unsupported completion shell %q, use bash, zsh or fish", shell)
+	}
+}
+
+// manPagePath returns where the man page is installed in dataHome, in the
+// section directory taken from its name, e.g. man/man1/tool.1.gz
+func manPagePath(dataHome string, page models.ManPage) (string, error) {
+	name := page.Name
+	if name == "" && page.Source != "" {
+		name = filepath.Base(filepath.FromSlash(page.Source))
+	}
+	if name == "" {
+		return "", errors.New("a man page needs a name when it is generated")
+	}
+
+	section := strings.TrimPrefix(filepath.Ext(strings.TrimSuffix(name, ".gz")), ".")
+	if section == "" || section[0] < '1' || section[0] > '9' {
+		return "", fmt.Errorf("no man section in the name of %s, e.g. tool.1", name)
+	}
+	return filepath.Join(dataHome, "man", "man"+section[:1], name), nil
+}
+
+// installDocs installs the shell completions and man pages of b. They are
+// recorded in b.InstalledFiles, so uninstall removes them. Nothing is
+// installed for another platform, generators can't run there.
+func installDocs(b *models.Binaries) error {
+	if len(b.Completions) == 0 && len(b.ManPages) == 0 {
+		return nil
+	}
+	if !isHostTarget(*b) {
+		logrus.Debugf("Skipping completions and man pages of %s, it is installed for %s/%s", b.Name, b.OsInfo.OS, b.OsInfo.Arch)
+		return nil
+	}
+
+	dataHome, err := fileio.DataHome()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range b.Completions {
+		dst, err := completionPath(dataHome, c.Shell, b.Name)
+		if err != nil {
+			return fmt.Errorf("failed to install completion of %s: %w", b.Name, err)
+		}
+		if err := installDoc(b, c.Source, c.Command, dst, c.Shell+" completion generator"); err != nil {
+			return err
+		}
+	}
+
+	for _, page := range b.ManPages {
+		dst, err := manPagePath(dataHome, page)
+		if err != nil {
+			return fmt.Errorf("failed to install man page of %s: %w", b.Name, err)
+		}
+		if err := installDoc(b, page.Source, page.Command, dst, "man page generator"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// installDoc copies source from the extracted download to dst, or writes the
+// output of command to it
+func installDoc(b *models.Binaries, source, command, dst, what string) error {
+	if err := mkdirAll(b, filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", dst, err)
+	}
+	if err := backupFile(b, dst); err != nil {
+		return err
+	}
+
+	switch {
+	case source != "":
+		rel := filepath.FromSlash(source)
+		file := models.File{FileName: filepath.Base(rel), SourcePath: rel}
+		src := resolveSingleFileSourcePath(*b, file, filepath.Join(b.DownloadFolder, rel))
+		if _, err := os.Stat(src); err != nil {
+			return fmt.Errorf("%s not found in the download of %s", source, b.Name)
+		}
+		logrus.Debugf("Copying %s to %s", src, dst)
+		if err := copyReplaceFile(src, dst, 0644); err != nil {
+			return err
+		}
+	case command != "":
+		out, err := runShell(what, command, b.Shell, b.Hooks, hookEnvFor(*b))
+		if err != nil {
+			return err
+		}
+		tmp, err := os.CreateTemp(b.DownloadFolder, filepath.Base(dst)+".*")
+		if err != nil {
+			return fmt.Errorf("failed to create a temporary file for %s: %w", dst, err)
+		}
+		_, err = tmp.Write(out)
+		if cerr := tmp.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+			return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+		}
+		logrus.Debugf("Writing the output of the %s to %s", what, dst)
+		if err := replaceFile(tmp.Name(), dst, 0644); err != nil {
+			_ = os.Remove(tmp.Name())
+			return err
+		}
+	default:
+		return fmt.Errorf("%s of %s needs a source or a command", dst, b.Name)
+	}
+
+	b.InstalledFiles = append(b.InstalledFiles, dst)
+	return nil
+}
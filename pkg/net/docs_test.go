@@ -0,0 +1,139 @@
+package net
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akshaybabloo/binstall/models"
+)
+
+func TestCompletionPath(t *testing.T) {
+	tests := []struct {
+		shell   string
+		want    string
+		wantErr bool
+	}{
+		{"bash", filepath.Join("data", "bash-completion", "completions", "tool"), false},
+		{"zsh", filepath.Join("data", "zsh", "site-functions", "_tool"), false},
+		{"Fish", filepath.Join("data", "fish", "vendor_completions.d", "tool.fish"), false},
+		{"tcsh", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			got, err := completionPath("data", tt.shell, "tool")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestManPagePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		page    models.ManPage
+		want    string
+		wantErr bool
+	}{
+		{"source", models.ManPage{Source: "man/tool.1"}, filepath.Join("data", "man", "man1", "tool.1"), false},
+		{"gzipped", models.ManPage{Source: "doc/tool-config.5.gz"}, filepath.Join("data", "man", "man5", "tool-config.5.gz"), false},
+		{"section_suffix", models.ManPage{Source: "Tool.3pm"}, filepath.Join("data", "man", "man3", "Tool.3pm"), false},
+		{"generated", models.ManPage{Command: "tool man", Name: "tool.8"}, filepath.Join("data", "man", "man8", "tool.8"), false},
+		{"generated_without_name", models.ManPage{Command: "tool man"}, "", true},
+		{"no_section", models.ManPage{Source: "man/tool.md"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := manPagePath("data", tt.page)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestInstallDocs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("generators are POSIX shell commands")
+	}
+
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	bashPath := filepath.Join(dataHome, "bash-completion", "completions", "tool")
+	zshPath := filepath.Join(dataHome, "zsh", "site-functions", "_tool")
+	manPath := filepath.Join(dataHome, "man", "man1", "tool.1")
+
+	// A completion of the previous version is backed up and restored on failure
+	require.NoError(t, os.MkdirAll(filepath.Dir(bashPath), 0o755))
+	require.NoError(t, os.WriteFile(bashPath, []byte("old completion"), 0o644))
+
+	downloadDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(downloadDir, "tool-1.0.0", "completions"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(downloadDir, "tool-1.0.0", "man"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(downloadDir, "tool-1.0.0", "completions", "tool.bash"), []byte("bash completion"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(downloadDir, "tool-1.0.0", "man", "tool.1"), []byte(".TH TOOL 1"), 0o644))
+
+	b := models.Binaries{
+		Name:            "tool",
+		NewVersion:      "v1.0.0",
+		DownloadFolder:  downloadDir,
+		InstallLocation: t.TempDir(),
+		BackupDir:       t.TempDir(),
+		Completions: []models.Completion{
+			{Shell: "bash", Source: "completions/tool.bash"},
+			{Shell: "zsh", Command: `echo "#compdef $BINSTALL_NAME $BINSTALL_VERSION"`},
+		},
+		ManPages: []models.ManPage{{Source: "man/tool.1"}},
+	}
+	require.NoError(t, installDocs(&b))
+
+	for path, want := range map[string]string{
+		bashPath: "bash completion",
+		zshPath:  "#compdef tool v1.0.0\n",
+		manPath:  ".TH TOOL 1",
+	} {
+		got, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, want, string(got))
+	}
+	assert.ElementsMatch(t, []string{bashPath, zshPath, manPath}, b.InstalledFiles)
+
+	_, err := restoreFiles(b.InstallLocation, b.BackupDir, b.InstalledFiles, b.InstalledDirs)
+	require.NoError(t, err)
+	got, err := os.ReadFile(bashPath)
+	require.NoError(t, err)
+	assert.Equal(t, "old completion", string(got))
+	assert.NoFileExists(t, zshPath)
+	assert.NoDirExists(t, filepath.Join(dataHome, "man"), "directories created for the docs are removed")
+
+	t.Run("missing_source", func(t *testing.T) {
+		b := models.Binaries{
+			Name:           "tool",
+			DownloadFolder: t.TempDir(),
+			ManPages:       []models.ManPage{{Source: "man/missing.1"}},
+		}
+		require.Error(t, installDocs(&b))
+	})
+
+	t.Run("failing_generator", func(t *testing.T) {
+		b := models.Binaries{
+			Name:           "tool",
+			DownloadFolder: t.TempDir(),
+			Completions:    []models.Completion{{Shell: "fish", Command: "echo no fish support >&2; exit 2"}},
+		}
+		err := installDocs(&b)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no fish support")
+	})
+}
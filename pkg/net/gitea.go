@@ -0,0 +1,111 @@
+package net
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg/utils"
+)
+
+// giteaRelease is the subset of the Gitea releases API response used by binstall.
+// Forgejo and Codeberg serve the same API.
+type giteaRelease struct {
+	TagName    string              `json:"tag_name"`
+	Draft      bool                `json:"draft"`
+	Prerelease bool                `json:"prerelease"`
+	Assets     []giteaReleaseAsset `json:"assets"`
+}
+
+// giteaReleaseAsset is a file attached to a Gitea release
+type giteaReleaseAsset struct {
+	Name               string `json:"name"`
+	Size               int64  `json:"size"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// newGiteaClient builds the resty client used to talk to the Gitea REST API
+func newGiteaClient(baseURL, token string) *resty.Client {
+	client := newRestyClient().SetBaseURL(baseURL + "/api/v1")
+	if token != "" {
+		client.SetHeader("Authorization", "token "+token)
+	}
+	return client
+}
+
+// latestGiteaRelease fetches the latest release for b from a Gitea, Forgejo
+// or Codeberg instance. GITEA_TOKEN is used for private repositories.
+func latestGiteaRelease(b models.Binaries) (release, error) {
+	info, err := utils.ExpandGiteaURL(b.URL)
+	if err != nil {
+		return release{}, err
+	}
+
+	var latest giteaRelease
+	client := newGiteaClient(info.BaseURL, os.Getenv("GITEA_TOKEN"))
+	resp, err := client.R().
+		SetResult(&latest).
+		Get("/repos/" + url.PathEscape(info.Owner) + "/" + url.PathEscape(info.Repo) + "/releases/latest")
+	if err != nil {
+		return release{}, fmt.Errorf("failed to get the latest Gitea release for %s/%s: %w", info.Owner, info.Repo, err)
+	}
+	if resp.IsError() {
+		return release{}, fmt.Errorf("failed to get the latest Gitea release for %s/%s: %s", info.Owner, info.Repo, resp.Status())
+	}
+
+	return latest.toRelease(), nil
+}
+
+// listGiteaReleases lists the published releases of b from Gitea, newest first
+func listGiteaReleases(b models.Binaries) ([]release, error) {
+	info, err := utils.ExpandGiteaURL(b.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	const limit = 50
+	client := newGiteaClient(info.BaseURL, os.Getenv("GITEA_TOKEN"))
+
+	var releases []release
+	for page := 1; page <= maxReleasePages; page++ {
+		var list []giteaRelease
+		resp, err := client.R().
+			SetResult(&list).
+			SetQueryParam("limit", strconv.Itoa(limit)).
+			SetQueryParam("page", strconv.Itoa(page)).
+			Get("/repos/" + url.PathEscape(info.Owner) + "/" + url.PathEscape(info.Repo) + "/releases")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Gitea releases for %s/%s: %w", info.Owner, info.Repo, err)
+		}
+		if resp.IsError() {
+			return nil, fmt.Errorf("failed to list Gitea releases for %s/%s: %s", info.Owner, info.Repo, resp.Status())
+		}
+		for _, r := range list {
+			if r.Draft {
+				continue
+			}
+			releases = append(releases, r.toRelease())
+		}
+		if len(list) < limit {
+			break
+		}
+	}
+	return releases, nil
+}
+
+// toRelease converts the Gitea response into a release
+func (r giteaRelease) toRelease() release {
+	rel := release{TagName: r.TagName, Prerelease: r.Prerelease}
+	for _, asset := range r.Assets {
+		rel.Assets = append(rel.Assets, releaseAsset{
+			Name: asset.Name,
+			URL:  asset.BrowserDownloadURL,
+			Size: asset.Size,
+		})
+	}
+	return rel
+}
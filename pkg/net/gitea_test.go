@@ -0,0 +1,96 @@
+package net
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg/utils"
+)
+
+// withGiteaServer starts an httptest server that serves rel from the
+// latest-release endpoint of owner/repo. The returned URL can be used as a
+// self-hosted Gitea base URL.
+func withGiteaServer(t *testing.T, rel giteaRelease) *httptest.Server {
+	t.Helper()
+	body, err := json.Marshal(rel)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/owner/repo/releases/latest" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLatestGiteaRelease(t *testing.T) {
+	t.Run("auto_detected_asset", func(t *testing.T) {
+		assetName := currentOSArchAssetName("tar.gz")
+		downloadURL := "https://codeberg.example.test/owner/repo/releases/download/v1.2.3/" + assetName
+		srv := withGiteaServer(t, giteaRelease{
+			TagName: "v1.2.3",
+			Assets:  []giteaReleaseAsset{{Name: assetName, Size: 10, BrowserDownloadURL: downloadURL}},
+		})
+
+		b := models.Binaries{URL: srv.URL + "/owner/repo", Provider: Gitea}
+		got, err := checkForNewVersion(b)
+		require.NoError(t, err)
+		assert.Equal(t, "v1.2.3", got.NewVersion)
+		assert.Equal(t, assetName, got.DownloadFileName)
+		assert.Equal(t, downloadURL, got.DownloadURL)
+	})
+
+	t.Run("explicit_provider_from_yaml", func(t *testing.T) {
+		assetName := currentOSArchAssetName("zip")
+		srv := withGiteaServer(t, giteaRelease{
+			TagName: "v2.0.0",
+			Assets:  []giteaReleaseAsset{{Name: assetName, BrowserDownloadURL: "https://example.test/" + assetName}},
+		})
+
+		b, err := utils.ParseYaml([]byte("name: tool\nurl: " + srv.URL + "/owner/repo\nprovider: forgejo\n"))
+		require.NoError(t, err)
+
+		got, err := ResolveBinary(b)
+		require.NoError(t, err)
+		assert.EqualValues(t, Gitea, got.Provider)
+		assert.Equal(t, "v2.0.0", got.NewVersion)
+	})
+
+	t.Run("http_error_is_returned", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		t.Cleanup(srv.Close)
+
+		b := models.Binaries{URL: srv.URL + "/owner/repo", Provider: Gitea}
+		_, err := checkForNewVersion(b)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "404")
+	})
+}
+
+func TestListGiteaReleases(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/owner/repo/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"tag_name":"v2.0.0","draft":true},{"tag_name":"v1.1.0","prerelease":true},{"tag_name":"v1.0.0"}]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	releases, err := listGiteaReleases(models.Binaries{URL: srv.URL + "/owner/repo"})
+	require.NoError(t, err)
+	require.Len(t, releases, 2, "drafts are skipped")
+	assert.Equal(t, "v1.1.0", releases[0].TagName)
+	assert.True(t, releases[0].Prerelease)
+}
@@ -0,0 +1,128 @@
+package net
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg/utils"
+)
+
+// gitLabRelease is the subset of the GitLab releases API response used by binstall
+type gitLabRelease struct {
+	TagName         string `json:"tag_name"`
+	UpcomingRelease bool   `json:"upcoming_release"`
+	Assets          struct {
+		Links []gitLabReleaseLink `json:"links"`
+	} `json:"assets"`
+}
+
+// gitLabReleaseLink is a release asset link. Links can point to uploads,
+// the generic package registry or any external URL.
+type gitLabReleaseLink struct {
+	Name           string `json:"name"`
+	URL            string `json:"url"`
+	DirectAssetURL string `json:"direct_asset_url"`
+	LinkType       string `json:"link_type"`
+}
+
+// newGitLabClient builds the resty client used to talk to the GitLab REST API.
+// The token, if any, is sent as a personal access token.
+func newGitLabClient(baseURL, token string) *resty.Client {
+	client := newRestyClient().SetBaseURL(baseURL + "/api/v4")
+	if token != "" {
+		client.SetHeader("PRIVATE-TOKEN", token)
+	}
+	return client
+}
+
+// latestGitLabRelease fetches the latest release for b from gitlab.com or a
+// self-hosted GitLab instance. GITLAB_TOKEN is used for private projects.
+func latestGitLabRelease(b models.Binaries) (release, error) {
+	info, err := utils.ExpandGitLabURL(b.URL)
+	if err != nil {
+		return release{}, err
+	}
+
+	var latest gitLabRelease
+	client := newGitLabClient(info.BaseURL, os.Getenv("GITLAB_TOKEN"))
+	resp, err := client.R().
+		SetResult(&latest).
+		Get("/projects/" + url.PathEscape(info.Project) + "/releases/permalink/latest")
+	if err != nil {
+		return release{}, fmt.Errorf("failed to get the latest GitLab release for %s: %w", info.Project, err)
+	}
+	if resp.IsError() {
+		return release{}, fmt.Errorf("failed to get the latest GitLab release for %s: %s", info.Project, resp.Status())
+	}
+
+	return latest.toRelease(), nil
+}
+
+// listGitLabReleases lists the releases of b from GitLab, newest first
+func listGitLabReleases(b models.Binaries) ([]release, error) {
+	info, err := utils.ExpandGitLabURL(b.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	client := newGitLabClient(info.BaseURL, os.Getenv("GITLAB_TOKEN"))
+
+	var releases []release
+	for page := 1; page <= maxReleasePages; page++ {
+		var list []gitLabRelease
+		resp, err := client.R().
+			SetResult(&list).
+			SetQueryParam("per_page", "100").
+			SetQueryParam("page", strconv.Itoa(page)).
+			Get("/projects/" + url.PathEscape(info.Project) + "/releases")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list GitLab releases for %s: %w", info.Project, err)
+		}
+		if resp.IsError() {
+			return nil, fmt.Errorf("failed to list GitLab releases for %s: %s", info.Project, resp.Status())
+		}
+		for _, r := range list {
+			releases = append(releases, r.toRelease())
+		}
+		if resp.Header().Get("X-Next-Page") == "" {
+			break
+		}
+	}
+	return releases, nil
+}
+
+// toRelease converts the GitLab response into a release. Package registry
+// links are often named after the platform ("Linux amd64") rather than the
+// file, so links without an extension fall back to the file name in the URL.
+func (r gitLabRelease) toRelease() release {
+	// Upcoming releases have a release date in the future and are not published yet
+	rel := release{TagName: r.TagName, Prerelease: r.UpcomingRelease}
+	for _, link := range r.Assets.Links {
+		assetURL := link.DirectAssetURL
+		if assetURL == "" {
+			assetURL = link.URL
+		}
+
+		name := link.Name
+		if filepath.Ext(name) == "" {
+			if u, err := url.Parse(assetURL); err == nil {
+				if base := path.Base(u.Path); base != "." && base != "/" {
+					name = base
+				}
+			}
+		}
+
+		rel.Assets = append(rel.Assets, releaseAsset{
+			Name: name,
+			URL:  assetURL,
+		})
+	}
+	return rel
+}
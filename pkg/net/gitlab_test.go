@@ -0,0 +1,138 @@
+package net
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akshaybabloo/binstall/models"
+)
+
+// withGitLabServer starts an httptest server that serves rel from the
+// latest-release permalink of any project. The returned URL can be used as a
+// self-hosted GitLab base URL.
+func withGitLabServer(t *testing.T, rel gitLabRelease) *httptest.Server {
+	t.Helper()
+	body, err := json.Marshal(rel)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/v4/projects/") || !strings.HasSuffix(r.URL.Path, "/releases/permalink/latest") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLatestGitLabRelease(t *testing.T) {
+	t.Run("asset_links", func(t *testing.T) {
+		assetName := currentOSArchAssetName("tar.gz")
+		rel := gitLabRelease{TagName: "v1.2.3"}
+		rel.Assets.Links = []gitLabReleaseLink{{
+			Name:           assetName,
+			URL:            "https://gitlab.example.test/uploads/" + assetName,
+			DirectAssetURL: "https://gitlab.example.test/owner/repo/-/releases/v1.2.3/downloads/" + assetName,
+		}}
+		srv := withGitLabServer(t, rel)
+
+		b := models.Binaries{URL: srv.URL + "/group/sub/repo", Provider: GitLab}
+		got, err := checkForNewVersion(b)
+		require.NoError(t, err)
+		assert.Equal(t, "v1.2.3", got.NewVersion)
+		assert.Equal(t, assetName, got.DownloadFileName)
+		assert.Equal(t, rel.Assets.Links[0].DirectAssetURL, got.DownloadURL)
+		assert.Equal(t, runtime.GOOS, got.OsInfo.OS)
+	})
+
+	t.Run("package_registry_link_uses_file_name_from_url", func(t *testing.T) {
+		fileName := currentOSArchAssetName("tar.gz")
+		pkgURL := "https://gitlab.example.test/api/v4/projects/42/packages/generic/tool/1.2.3/" + fileName
+		rel := gitLabRelease{TagName: "v1.2.3"}
+		rel.Assets.Links = []gitLabReleaseLink{{Name: "Linux binary", URL: pkgURL, LinkType: "package"}}
+		srv := withGitLabServer(t, rel)
+
+		b := models.Binaries{URL: srv.URL + "/owner/repo", Provider: GitLab}
+		got, err := checkForNewVersion(b)
+		require.NoError(t, err)
+		assert.Equal(t, fileName, got.DownloadFileName)
+		assert.Equal(t, pkgURL, got.DownloadURL)
+	})
+
+	t.Run("configured_download_filename_match", func(t *testing.T) {
+		assetName := "release-v1.2.3-custom.tar.gz"
+		rel := gitLabRelease{TagName: "v1.2.3"}
+		rel.Assets.Links = []gitLabReleaseLink{{Name: assetName, URL: "https://example.test/" + assetName}}
+		srv := withGitLabServer(t, rel)
+
+		b := models.Binaries{
+			URL:      srv.URL + "/owner/repo",
+			Provider: GitLab,
+			Download: map[string]map[string]models.DownloadArchInfo{
+				runtime.GOOS: {archKeyForCurrent(t): {FileName: "release-{{.Version}}-custom.tar.gz"}},
+			},
+		}
+		got, err := checkForNewVersion(b)
+		require.NoError(t, err)
+		assert.Equal(t, assetName, got.DownloadFileName)
+	})
+
+	t.Run("http_error_is_returned", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		t.Cleanup(srv.Close)
+
+		b := models.Binaries{URL: srv.URL + "/owner/repo", Provider: GitLab}
+		_, err := checkForNewVersion(b)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "404")
+	})
+
+	t.Run("no_matching_asset_returns_ErrNetBinaryNotFound", func(t *testing.T) {
+		rel := gitLabRelease{TagName: "v1.2.3"}
+		rel.Assets.Links = []gitLabReleaseLink{{Name: "tool-plan9-mips.tar.gz", URL: "https://example.test/none"}}
+		srv := withGitLabServer(t, rel)
+
+		b := models.Binaries{URL: srv.URL + "/owner/repo", Provider: GitLab}
+		_, err := checkForNewVersion(b)
+		require.Error(t, err)
+	})
+}
+
+func TestListGitLabReleases(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/releases") {
+			http.NotFound(w, r)
+			return
+		}
+		var body string
+		switch r.URL.Query().Get("page") {
+		case "1":
+			w.Header().Set("X-Next-Page", "2")
+			body = `[{"tag_name":"v1.6.0","upcoming_release":true},{"tag_name":"v1.5.0"}]`
+		default:
+			body = `[{"tag_name":"v1.4.0"}]`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	releases, err := listGitLabReleases(models.Binaries{URL: srv.URL + "/owner/repo"})
+	require.NoError(t, err)
+	require.Len(t, releases, 3)
+	assert.True(t, releases[0].Prerelease)
+	assert.Equal(t, "v1.4.0", releases[2].TagName)
+
+	got, err := pickRelease(releases, ">= 1.0", false)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.5.0", got.TagName)
+}
@@ -0,0 +1,118 @@
+package net
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/akshaybabloo/binstall/models"
+)
+
+// defaultHookTimeout is how long a hook may run when no timeout is configured
+const defaultHookTimeout = time.Minute
+
+// hookEnv describes the install to a hook
+type hookEnv struct {
+	Name        string
+	Version     string
+	InstallDir  string
+	DownloadDir string
+}
+
+// hookEnvFor returns the hook environment of an install of b
+func hookEnvFor(b models.Binaries) hookEnv {
+	return hookEnv{
+		Name:        b.Name,
+		Version:     tagVersion(b, b.NewVersion),
+		InstallDir:  b.InstallLocation,
+		DownloadDir: b.DownloadFolder,
+	}
+}
+
+// runHook runs the command of the stage hook with shell. The hook is killed
+// when it runs longer than the configured timeout, and its output is part of
+// the returned error when it fails.
+func runHook(stage, command, shell string, hooks models.Hooks, env hookEnv) error {
+	if strings.TrimSpace(command) == "" {
+		return nil
+	}
+	out, err := runShell(stage+" hook", command, shell, hooks, env)
+	if err != nil {
+		return err
+	}
+	logrus.Debugf("%s hook of %s output: %s", stage, env.Name, out)
+	return nil
+}
+
+// runShell runs command with shell like a hook and returns its standard
+// output. what names the command in errors.
+func runShell(what, command, shell string, hooks models.Hooks, env hookEnv) ([]byte, error) {
+	timeout := defaultHookTimeout
+	if hooks.Timeout != "" {
+		d, err := time.ParseDuration(hooks.Timeout)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid hook timeout %q of %s", hooks.Timeout, env.Name)
+		}
+		timeout = d
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	args := shellArgs(shell)
+	cmd := exec.CommandContext(ctx, args[0], append(args[1:], command)...)
+	cmd.Env = append(os.Environ(),
+		"BINSTALL_NAME="+env.Name,
+		"BINSTALL_VERSION="+env.Version,
+		"BINSTALL_INSTALL_DIR="+env.InstallDir,
+		"BINSTALL_DOWNLOAD_DIR="+env.DownloadDir,
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Children that keep the output open don't block the command past its timeout
+	cmd.WaitDelay = time.Second
+
+	logrus.Debugf("Running %s of %s: %s", what, env.Name, command)
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%s of %s timed out after %s\nOutput: %s%s", what, env.Name, timeout, stdout.Bytes(), stderr.Bytes())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s of %s failed: %w\nOutput: %s%s", what, env.Name, err, stdout.Bytes(), stderr.Bytes())
+	}
+	return stdout.Bytes(), nil
+}
+
+// shellArgs returns the command line that runs a hook command with shell,
+// the command is appended to it. A shell given with arguments is used as is.
+func shellArgs(shell string) []string {
+	args := strings.Fields(shell)
+	if len(args) == 0 {
+		if runtime.GOOS == "windows" {
+			return []string{"cmd", "/C"}
+		}
+		return []string{"sh", "-c"}
+	}
+	if len(args) > 1 {
+		return args
+	}
+
+	switch strings.TrimSuffix(strings.ToLower(filepath.Base(args[0])), ".exe") {
+	case "cmd":
+		return append(args, "/C")
+	case "powershell", "pwsh":
+		return append(args, "-Command")
+	default:
+		return append(args, "-c")
+	}
+}
@@ -0,0 +1,157 @@
+package net
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akshaybabloo/binstall/models"
+)
+
+func TestShellArgs(t *testing.T) {
+	tests := []struct {
+		shell string
+		want  []string
+	}{
+		{"bash", []string{"bash", "-c"}},
+		{"/usr/bin/zsh", []string{"/usr/bin/zsh", "-c"}},
+		{"pwsh", []string{"pwsh", "-Command"}},
+		{"cmd.exe", []string{"cmd.exe", "/C"}},
+		{"bash -eu -c", []string{"bash", "-eu", "-c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			assert.Equal(t, tt.want, shellArgs(tt.shell))
+		})
+	}
+
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, []string{"sh", "-c"}, shellArgs(""))
+	}
+}
+
+func TestRunHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks are POSIX shell commands")
+	}
+	env := hookEnv{Name: "tool", Version: "1.2.3", InstallDir: "/opt/bin", DownloadDir: "/tmp/tool"}
+
+	t.Run("environment", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "env")
+		cmd := `echo "$BINSTALL_NAME $BINSTALL_VERSION $BINSTALL_INSTALL_DIR $BINSTALL_DOWNLOAD_DIR" > ` + out
+		require.NoError(t, runHook("postInstall", cmd, "", models.Hooks{}, env))
+
+		got, err := os.ReadFile(out)
+		require.NoError(t, err)
+		assert.Equal(t, "tool 1.2.3 /opt/bin /tmp/tool\n", string(got))
+	})
+
+	t.Run("empty_command_is_skipped", func(t *testing.T) {
+		require.NoError(t, runHook("preInstall", " ", "does-not-exist", models.Hooks{}, env))
+	})
+
+	t.Run("failure_includes_output", func(t *testing.T) {
+		err := runHook("postInstall", "echo broken config; exit 3", "", models.Hooks{}, env)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "postInstall hook of tool failed")
+		assert.Contains(t, err.Error(), "broken config")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		err := runHook("postInstall", "echo started; sleep 10", "", models.Hooks{Timeout: "100ms"}, env)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "timed out after 100ms")
+		assert.Contains(t, err.Error(), "started")
+	})
+
+	t.Run("invalid_timeout", func(t *testing.T) {
+		require.Error(t, runHook("postInstall", "true", "", models.Hooks{Timeout: "soon"}, env))
+	})
+}
+
+func TestInstallDownloaded_Hooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("end-to-end install relies on POSIX shell scripts")
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "release.tar.gz")
+	makeTarGz(t, archivePath, map[string]string{"tool": "new"})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, archivePath)
+	}))
+	t.Cleanup(srv.Close)
+
+	installDir := t.TempDir()
+	hookLog := filepath.Join(t.TempDir(), "hooks.log")
+	require.NoError(t, os.WriteFile(filepath.Join(installDir, "tool"), []byte("old"), 0o755))
+
+	b := models.Binaries{
+		Name:             uniqueTempName(t),
+		NewVersion:       "1.2.3",
+		DownloadURL:      srv.URL + "/release.tar.gz",
+		DownloadFileName: "release.tar.gz",
+		ContentType:      "application/gzip",
+		InstallLocation:  installDir,
+		Files:            []models.File{{FileName: "tool", CopyIt: true}},
+		Hooks: models.Hooks{
+			PreInstall:  `cat "$BINSTALL_INSTALL_DIR/tool" >> ` + hookLog,
+			PostInstall: `cat "$BINSTALL_INSTALL_DIR/tool" >> ` + hookLog,
+		},
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dl, err := DownloadBinary(b)
+	require.NoError(t, err)
+
+	t.Run("pre_and_post_install", func(t *testing.T) {
+		installed, err := InstallDownloaded(dl)
+		require.NoError(t, err)
+
+		got, err := os.ReadFile(hookLog)
+		require.NoError(t, err)
+		assert.Equal(t, "oldnew", strings.ReplaceAll(string(got), "\n", ""))
+
+		entry, err := InstalledBinaryFor(installed)
+		require.NoError(t, err)
+		assert.Equal(t, b.Hooks, entry.Hooks)
+	})
+
+	t.Run("failed_post_install_restores", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(installDir, "tool"), []byte("old"), 0o755))
+		failing := dl
+		failing.Hooks = models.Hooks{PostInstall: "echo restart failed; exit 1"}
+
+		_, err := InstallDownloaded(failing)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "restart failed")
+
+		got, err := os.ReadFile(filepath.Join(installDir, "tool"))
+		require.NoError(t, err)
+		assert.Equal(t, "old", string(got))
+	})
+}
+
+func TestUninstall_PostUninstallHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks are POSIX shell commands")
+	}
+
+	marker := filepath.Join(t.TempDir(), "uninstalled")
+	installed := models.InstalledBinary{
+		Name:            "tool",
+		Version:         "1.0.0",
+		InstallLocation: t.TempDir(),
+		Hooks:           models.Hooks{PostUninstall: `echo "$BINSTALL_NAME $BINSTALL_VERSION" > ` + marker},
+	}
+	require.NoError(t, Uninstall(installed, false))
+
+	got, err := os.ReadFile(marker)
+	require.NoError(t, err)
+	assert.Equal(t, "tool 1.0.0\n", string(got))
+}
@@ -0,0 +1,106 @@
+package net
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg"
+	"github.com/akshaybabloo/binstall/pkg/utils"
+)
+
+// latestGenericVersion fetches Generic.VersionURL and extracts the latest
+// version from a response header, a JSON path or the plain body, optionally
+// narrowed down with Generic.VersionRegex.
+func latestGenericVersion(b models.Binaries) (string, error) {
+	g := b.Generic
+
+	client := newRestyClient()
+	if g.VersionHeader != "" {
+		// Stop at the first response so the Location header of a redirect can be read
+		client.SetRedirectPolicy(resty.RedirectPolicyFunc(func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}))
+	}
+
+	resp, err := client.R().Get(g.VersionURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the latest version for %s: %w", b.Name, err)
+	}
+	if resp.IsError() {
+		return "", fmt.Errorf("failed to get the latest version for %s: %s", b.Name, resp.Status())
+	}
+
+	var value string
+	switch {
+	case g.VersionHeader != "":
+		value = resp.Header().Get(g.VersionHeader)
+		if value == "" {
+			return "", fmt.Errorf("header %s not found in the version response for %s", g.VersionHeader, b.Name)
+		}
+	case g.VersionJSONPath != "":
+		value, err = utils.LookupJSONPath(resp.Body(), g.VersionJSONPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the version for %s: %w", b.Name, err)
+		}
+	default:
+		value = string(resp.Body())
+	}
+
+	if g.VersionRegex != "" {
+		value, err = utils.MatchVersion(value, g.VersionRegex)
+		if err != nil {
+			return "", fmt.Errorf("failed to extract the version for %s: %w", b.Name, err)
+		}
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("empty version returned for %s", b.Name)
+	}
+	return value, nil
+}
+
+// checkGenericVersion resolves the latest version and download URL of a
+// binary served from plain URLs. A pinned Version is used as is, since there
+// is no release list to match constraints against. Returns
+// pkg.ErrNetBinaryNotFound when the binary has no generic source configured.
+func checkGenericVersion(b models.Binaries) (models.Binaries, error) {
+	if b.Generic.DownloadURL == "" || (b.Generic.VersionURL == "" && b.Version == "") {
+		return models.Binaries{}, pkg.ErrNetBinaryNotFound
+	}
+
+	latest := strings.TrimSpace(b.Version)
+	if latest != "" {
+		if strings.ContainsAny(latest, "<>=~!, ") {
+			return models.Binaries{}, fmt.Errorf("the others provider only supports exact versions, got %q for %s", latest, b.Name)
+		}
+	} else {
+		var err error
+		latest, err = latestGenericVersion(b)
+		if err != nil {
+			return models.Binaries{}, err
+		}
+	}
+
+	downloadURL, err := renderTemplate(b, b.Generic.DownloadURL, latest)
+	if err != nil {
+		return models.Binaries{}, fmt.Errorf("failed to render the download URL for %s: %w", b.Name, err)
+	}
+
+	u, err := url.Parse(downloadURL)
+	if err != nil {
+		return models.Binaries{}, fmt.Errorf("invalid download URL for %s: %w", b.Name, err)
+	}
+
+	b.NewVersion = latest
+	b.DownloadURL = downloadURL
+	b.DownloadFileName = path.Base(u.Path)
+	b.OsInfo = utils.FigureOutOSAndArch(b.DownloadFileName)
+	return b, nil
+}
@@ -0,0 +1,153 @@
+package net
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg"
+)
+
+func TestCheckGenericVersion(t *testing.T) {
+	t.Run("plain_text_with_regex", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("latest release: v1.4.2\n"))
+		}))
+		t.Cleanup(srv.Close)
+
+		b := models.Binaries{
+			Name:     "tool",
+			Provider: Others,
+			Generic: models.GenericSource{
+				VersionURL:   srv.URL + "/latest.txt",
+				VersionRegex: `v(\d+\.\d+\.\d+)`,
+				DownloadURL:  "https://dl.example.test/tool/{{.Version}}/tool_{{.Version}}_linux_amd64.tar.gz",
+			},
+		}
+		got, err := checkForNewVersion(b)
+		require.NoError(t, err)
+		assert.Equal(t, "1.4.2", got.NewVersion)
+		assert.Equal(t, "https://dl.example.test/tool/1.4.2/tool_1.4.2_linux_amd64.tar.gz", got.DownloadURL)
+		assert.Equal(t, "tool_1.4.2_linux_amd64.tar.gz", got.DownloadFileName)
+		assert.Equal(t, models.OSArch{OS: "linux", Arch: "amd64"}, got.OsInfo)
+	})
+
+	t.Run("plain_text_without_regex_is_trimmed", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("  2.0.0\n"))
+		}))
+		t.Cleanup(srv.Close)
+
+		b := models.Binaries{
+			Provider: Others,
+			Generic:  models.GenericSource{VersionURL: srv.URL, DownloadURL: "https://dl.example.test/{{.Version}}/tool.zip"},
+		}
+		got, err := checkForNewVersion(b)
+		require.NoError(t, err)
+		assert.Equal(t, "2.0.0", got.NewVersion)
+	})
+
+	t.Run("json_path", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"releases":[{"version":"3.1.0"},{"version":"3.0.0"}]}`))
+		}))
+		t.Cleanup(srv.Close)
+
+		b := models.Binaries{
+			Provider: Others,
+			Generic: models.GenericSource{
+				VersionURL:      srv.URL,
+				VersionJSONPath: "$.releases[0].version",
+				DownloadURL:     "https://dl.example.test/{{.Version}}/tool.tar.gz",
+			},
+		}
+		got, err := checkForNewVersion(b)
+		require.NoError(t, err)
+		assert.Equal(t, "3.1.0", got.NewVersion)
+	})
+
+	t.Run("location_header_is_not_followed", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/latest" {
+				http.Redirect(w, r, "/releases/tag/v0.9.1", http.StatusFound)
+				return
+			}
+			http.NotFound(w, r)
+		}))
+		t.Cleanup(srv.Close)
+
+		b := models.Binaries{
+			Provider: Others,
+			Generic: models.GenericSource{
+				VersionURL:    srv.URL + "/latest",
+				VersionHeader: "Location",
+				VersionRegex:  `tag/(v[\d.]+)$`,
+				DownloadURL:   "https://dl.example.test/{{.Version}}/tool.tar.gz",
+			},
+		}
+		got, err := checkForNewVersion(b)
+		require.NoError(t, err)
+		assert.Equal(t, "v0.9.1", got.NewVersion)
+	})
+
+	t.Run("http_error_is_returned", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		t.Cleanup(srv.Close)
+
+		b := models.Binaries{
+			Provider: Others,
+			Generic:  models.GenericSource{VersionURL: srv.URL, DownloadURL: "https://dl.example.test/tool.tar.gz"},
+		}
+		_, err := checkForNewVersion(b)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "404")
+	})
+
+	t.Run("regex_without_match_returns_error", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("nothing here"))
+		}))
+		t.Cleanup(srv.Close)
+
+		b := models.Binaries{
+			Provider: Others,
+			Generic:  models.GenericSource{VersionURL: srv.URL, VersionRegex: `\d+\.\d+`, DownloadURL: "https://dl.example.test/tool.tar.gz"},
+		}
+		_, err := checkForNewVersion(b)
+		require.Error(t, err)
+	})
+
+	t.Run("pinned_exact_version_skips_version_url", func(t *testing.T) {
+		b := models.Binaries{
+			Provider: Others,
+			Version:  "1.2.0",
+			Generic:  models.GenericSource{VersionURL: "http://127.0.0.1:0/unreachable", DownloadURL: "https://dl.example.test/{{.Version}}/tool.tar.gz"},
+		}
+		got, err := checkForNewVersion(b)
+		require.NoError(t, err)
+		assert.Equal(t, "1.2.0", got.NewVersion)
+		assert.Equal(t, "https://dl.example.test/1.2.0/tool.tar.gz", got.DownloadURL)
+	})
+
+	t.Run("pinned_constraint_is_rejected", func(t *testing.T) {
+		b := models.Binaries{
+			Provider: Others,
+			Version:  "~> 1.2",
+			Generic:  models.GenericSource{DownloadURL: "https://dl.example.test/{{.Version}}/tool.tar.gz"},
+		}
+		_, err := checkForNewVersion(b)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only supports exact versions")
+	})
+
+	t.Run("not_configured_returns_ErrNetBinaryNotFound", func(t *testing.T) {
+		b := models.Binaries{Provider: Others}
+		_, err := checkForNewVersion(b)
+		assert.ErrorIs(t, err, pkg.ErrNetBinaryNotFound)
+	})
+}
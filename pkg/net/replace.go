@@ -0,0 +1,124 @@
+package net
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+)
+
+// replaceFile moves src over dst without a window where dst is missing or
+// partially written: src is staged in a temporary file in the directory of
+// dst, synced to disk and renamed over dst. src is renamed into place when
+// it is on the same filesystem and copied otherwise. An existing dst keeps
+// its mode and ownership, a new one gets mode.
+func replaceFile(src, dst string, mode os.FileMode) error {
+	return stageAndReplace(src, dst, mode, true)
+}
+
+// copyReplaceFile is replaceFile that leaves src in place
+func copyReplaceFile(src, dst string, mode os.FileMode) error {
+	return stageAndReplace(src, dst, mode, false)
+}
+
+func stageAndReplace(src, dst string, mode os.FileMode, move bool) error {
+	if _, err := os.Stat(src); err != nil {
+		return err
+	}
+
+	existing, err := os.Stat(dst)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to stat %s: %w", dst, err)
+	}
+	if existing != nil {
+		mode = existing.Mode()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".binstall-*")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary file for %s: %w", dst, err)
+	}
+	tmpPath := tmp.Name()
+	_ = tmp.Close()
+	committed := false
+	defer func() {
+		if !committed {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	renamed := false
+	if move {
+		// Renames fail across filesystems, e.g. from a tmpfs /tmp, the file is copied then
+		if err := os.Rename(src, tmpPath); err == nil {
+			renamed = true
+		} else {
+			logrus.Debugf("Can't rename %s to %s, copying it instead: %s", src, tmpPath, err)
+		}
+	}
+	if !renamed {
+		if err := copyInto(src, tmpPath); err != nil {
+			return err
+		}
+	}
+
+	if err := syncFile(tmpPath); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, mode.Perm()); err != nil {
+		return fmt.Errorf("failed to set mode on %s: %w", dst, err)
+	}
+	if existing != nil {
+		if err := chownLike(tmpPath, existing); err != nil {
+			logrus.Debugf("Can't keep the owner of %s: %s", dst, err)
+		}
+	}
+
+	if err := os.Rename(tmpPath, dst); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", dst, err)
+	}
+	committed = true
+	syncDir(filepath.Dir(dst))
+
+	if move && !renamed {
+		if err := os.Remove(src); err != nil {
+			return fmt.Errorf("failed to remove %s after copying it: %w", src, err)
+		}
+	}
+	return nil
+}
+
+// copyInto copies the contents of src to the existing file dst
+func copyInto(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open source file %s: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", dst, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to copy %s to %s: %w", src, dst, err)
+	}
+	return out.Close()
+}
+
+// syncFile flushes the contents of the file at p to disk
+func syncFile(p string) error {
+	f, err := os.OpenFile(p, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", p, err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to sync %s: %w", p, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,105 @@
+package net
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaceFile(t *testing.T) {
+	t.Run("new_file_gets_mode", func(t *testing.T) {
+		dir := t.TempDir()
+		src := filepath.Join(dir, "src")
+		dst := filepath.Join(dir, "dst")
+		require.NoError(t, os.WriteFile(src, []byte("new"), 0o600))
+
+		require.NoError(t, replaceFile(src, dst, 0o755))
+		assert.NoFileExists(t, src)
+		got, err := os.ReadFile(dst)
+		require.NoError(t, err)
+		assert.Equal(t, "new", string(got))
+		if runtime.GOOS != "windows" {
+			info, err := os.Stat(dst)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+		}
+	})
+
+	t.Run("existing_file_keeps_mode_and_open_handles", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("open files can't be replaced on Windows")
+		}
+		dir := t.TempDir()
+		src := filepath.Join(dir, "src")
+		dst := filepath.Join(dir, "dst")
+		require.NoError(t, os.WriteFile(src, []byte("new"), 0o755))
+		require.NoError(t, os.WriteFile(dst, []byte("old"), 0o750))
+
+		// A running binary keeps its file, the new one is renamed over it
+		f, err := os.Open(dst)
+		require.NoError(t, err)
+		defer f.Close()
+
+		require.NoError(t, replaceFile(src, dst, 0o755))
+
+		old, err := io.ReadAll(f)
+		require.NoError(t, err)
+		assert.Equal(t, "old", string(old))
+		got, err := os.ReadFile(dst)
+		require.NoError(t, err)
+		assert.Equal(t, "new", string(got))
+		info, err := os.Stat(dst)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o750), info.Mode().Perm())
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "no temporary files are left behind")
+	})
+
+	t.Run("across_filesystems", func(t *testing.T) {
+		shm, err := os.MkdirTemp("/dev/shm", "binstall-")
+		if err != nil {
+			t.Skip("no /dev/shm to move files across filesystems")
+		}
+		t.Cleanup(func() { _ = os.RemoveAll(shm) })
+
+		src := filepath.Join(shm, "src")
+		dst := filepath.Join(t.TempDir(), "dst")
+		require.NoError(t, os.WriteFile(src, []byte("data"), 0o755))
+
+		require.NoError(t, replaceFile(src, dst, 0o755))
+		assert.NoFileExists(t, src)
+		got, err := os.ReadFile(dst)
+		require.NoError(t, err)
+		assert.Equal(t, "data", string(got))
+	})
+
+	t.Run("copy_keeps_source", func(t *testing.T) {
+		dir := t.TempDir()
+		src := filepath.Join(dir, "src")
+		dst := filepath.Join(t.TempDir(), "dst")
+		require.NoError(t, os.WriteFile(src, []byte("data"), 0o644))
+
+		require.NoError(t, copyReplaceFile(src, dst, 0o644))
+		assert.FileExists(t, src)
+		got, err := os.ReadFile(dst)
+		require.NoError(t, err)
+		assert.Equal(t, "data", string(got))
+	})
+
+	t.Run("missing_source", func(t *testing.T) {
+		dir := t.TempDir()
+		err := replaceFile(filepath.Join(dir, "missing"), filepath.Join(dir, "dst"), 0o755)
+		require.ErrorIs(t, err, os.ErrNotExist)
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
@@ -0,0 +1,31 @@
+//go:build !windows
+
+package net
+
+import (
+	"os"
+	"syscall"
+)
+
+// chownLike gives the file at p the owner and group of info
+func chownLike(p string, info os.FileInfo) error {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	if int(st.Uid) == os.Getuid() && int(st.Gid) == os.Getgid() {
+		return nil
+	}
+	return os.Chown(p, int(st.Uid), int(st.Gid))
+}
+
+// syncDir flushes a rename in dir to disk. It is best effort, not every
+// filesystem supports syncing directories.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
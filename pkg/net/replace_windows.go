@@ -0,0 +1,13 @@
+//go:build windows
+
+package net
+
+import "os"
+
+// chownLike is a no-op, Windows files have no Unix owner
+func chownLike(p string, info os.FileInfo) error {
+	return nil
+}
+
+// syncDir is a no-op, directories can't be synced on Windows
+func syncDir(dir string) {}
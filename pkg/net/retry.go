@@ -0,0 +1,95 @@
+package net
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// RetryPolicy controls how failed requests to the release APIs and
+// interrupted downloads are retried
+type RetryPolicy struct {
+	Count   int           // Retries after the first attempt, 0 disables retrying
+	Wait    time.Duration // Wait before the first retry, doubled for every following one
+	MaxWait time.Duration // Upper bound of the wait between two attempts
+}
+
+// Retry is the retry policy of every request made by the package
+var Retry = RetryPolicy{Count: 3, Wait: time.Second, MaxWait: 30 * time.Second}
+
+// wait returns how long to wait before retry number attempt, starting at 1.
+// The Retry-After header of the failed response is honoured, up to MaxWait.
+func (p RetryPolicy) wait(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+			return min(time.Duration(secs)*time.Second, p.MaxWait)
+		}
+	}
+	wait := p.Wait
+	for i := 1; i < attempt && wait < p.MaxWait; i++ {
+		wait *= 2
+	}
+	return min(wait, p.MaxWait)
+}
+
+// retryableStatus reports whether a response with this status is worth
+// retrying. A 403 is only retried when the server says when to, as GitHub
+// does for its secondary rate limits.
+func retryableStatus(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	case http.StatusForbidden:
+		return resp.Header.Get("Retry-After") != ""
+	}
+	return false
+}
+
+// retryTransport retries GET and HEAD requests that failed with a network
+// error or a retryable status, following Retry
+type retryTransport struct {
+	base http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return t.base.RoundTrip(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if attempt > Retry.Count || req.Context().Err() != nil {
+			return resp, err
+		}
+		if err == nil && !retryableStatus(resp) {
+			return resp, nil
+		}
+
+		wait := Retry.wait(attempt, resp)
+		if err != nil {
+			logrus.Debugf("retrying %s in %s: %v", req.URL, wait, err)
+		} else {
+			logrus.Debugf("retrying %s in %s: %s", req.URL, wait, resp.Status)
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			_ = resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// newRestyClient returns a resty client that retries its requests
+func newRestyClient() *resty.Client {
+	return resty.New().SetTransport(&retryTransport{base: http.DefaultTransport})
+}
@@ -0,0 +1,252 @@
+package net
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akshaybabloo/binstall/models"
+)
+
+// withRetry replaces the package retry policy for the duration of the test
+func withRetry(t *testing.T, p RetryPolicy) {
+	t.Helper()
+	old := Retry
+	Retry = p
+	t.Cleanup(func() { Retry = old })
+}
+
+// dropAfter writes the first n bytes of content with the Content-Length of
+// the whole content, then drops the connection
+func dropAfter(w http.ResponseWriter, content []byte, n int) {
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.Header().Set("Content-Type", "application/octet-stream")
+	_, _ = w.Write(content[:n])
+	w.(http.Flusher).Flush()
+	panic(http.ErrAbortHandler)
+}
+
+func TestRetryPolicyWait(t *testing.T) {
+	p := RetryPolicy{Count: 5, Wait: time.Second, MaxWait: 5 * time.Second}
+	assert.Equal(t, time.Second, p.wait(1, nil))
+	assert.Equal(t, 2*time.Second, p.wait(2, nil))
+	assert.Equal(t, 4*time.Second, p.wait(3, nil))
+	assert.Equal(t, 5*time.Second, p.wait(4, nil), "capped at MaxWait")
+
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"3"}}}
+	assert.Equal(t, 3*time.Second, p.wait(1, resp))
+	resp.Header.Set("Retry-After", "120")
+	assert.Equal(t, 5*time.Second, p.wait(1, resp), "Retry-After is capped at MaxWait")
+}
+
+func TestRetryTransport(t *testing.T) {
+	withRetry(t, RetryPolicy{Count: 2, Wait: time.Millisecond, MaxWait: time.Millisecond})
+
+	t.Run("retries_server_errors", func(t *testing.T) {
+		var hits atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if hits.Add(1) < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_, _ = w.Write([]byte("ok"))
+		}))
+		t.Cleanup(srv.Close)
+
+		resp, err := newRestyClient().R().Get(srv.URL)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Equal(t, "ok", resp.String())
+		assert.EqualValues(t, 3, hits.Load())
+	})
+
+	t.Run("gives_up_after_count", func(t *testing.T) {
+		var hits atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			hits.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		t.Cleanup(srv.Close)
+
+		resp, err := newRestyClient().R().Get(srv.URL)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode())
+		assert.EqualValues(t, 3, hits.Load())
+	})
+
+	t.Run("client_errors_are_not_retried", func(t *testing.T) {
+		var hits atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			http.NotFound(w, r)
+		}))
+		t.Cleanup(srv.Close)
+
+		resp, err := newRestyClient().R().Get(srv.URL)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+		assert.EqualValues(t, 1, hits.Load())
+	})
+
+	t.Run("github_client", func(t *testing.T) {
+		var hits atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if hits.Add(1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"tag_name": "v1.0.0"}`))
+		}))
+		t.Cleanup(srv.Close)
+
+		c, err := newGitHubClient("", models.GitHubEnterprise{BaseURL: srv.URL + "/"})
+		require.NoError(t, err)
+		rel, _, err := c.Repositories.GetLatestRelease(context.Background(), "owner", "repo")
+		require.NoError(t, err)
+		assert.Equal(t, "v1.0.0", rel.GetTagName())
+		assert.EqualValues(t, 2, hits.Load())
+	})
+}
+
+func TestDownloadFile_Retry(t *testing.T) {
+	withRetry(t, RetryPolicy{Count: 2, Wait: time.Millisecond, MaxWait: time.Millisecond})
+	content := bytes.Repeat([]byte("0123456789"), 10_000)
+
+	t.Run("resumes_with_range", func(t *testing.T) {
+		var ranges []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ranges = append(ranges, r.Header.Get("Range"))
+			if len(ranges) == 1 {
+				dropAfter(w, content, 40_000)
+			}
+			http.ServeContent(w, r, "tool.tar.gz", time.Time{}, bytes.NewReader(content))
+		}))
+		t.Cleanup(srv.Close)
+
+		b := models.Binaries{
+			Name:             uniqueTempName(t),
+			DownloadFileName: "tool.tar.gz",
+			DownloadURL:      srv.URL + "/tool.tar.gz",
+			DownloadSize:     int64(len(content)),
+		}
+		got, err := downloadFile(b)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(got.DownloadFilePath)
+		require.NoError(t, err)
+		assert.Equal(t, content, data)
+		assert.Equal(t, []string{"", "bytes=40000-"}, ranges)
+	})
+
+	t.Run("starts_over_without_range_support", func(t *testing.T) {
+		var hits atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if hits.Add(1) == 1 {
+				dropAfter(w, content, 40_000)
+			}
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write(content)
+		}))
+		t.Cleanup(srv.Close)
+
+		b := models.Binaries{
+			Name:             uniqueTempName(t),
+			DownloadFileName: "tool.tar.gz",
+			DownloadURL:      srv.URL + "/tool.tar.gz",
+		}
+		got, err := downloadFile(b)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(got.DownloadFilePath)
+		require.NoError(t, err)
+		assert.Equal(t, content, data)
+		assert.EqualValues(t, 2, hits.Load())
+	})
+
+	t.Run("gives_up_after_count", func(t *testing.T) {
+		var hits atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			hits.Add(1)
+			dropAfter(w, content, 10)
+		}))
+		t.Cleanup(srv.Close)
+
+		b := models.Binaries{
+			Name:             uniqueTempName(t),
+			DownloadFileName: "tool.tar.gz",
+			DownloadURL:      srv.URL + "/tool.tar.gz",
+		}
+		_, err := downloadFile(b)
+		require.Error(t, err)
+		assert.EqualValues(t, 3, hits.Load())
+		assert.NoFileExists(t, filepath.Join(os.TempDir(), b.Name, "tool.tar.gz"))
+	})
+
+	t.Run("retries_server_errors", func(t *testing.T) {
+		var hits atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if hits.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write(content)
+		}))
+		t.Cleanup(srv.Close)
+
+		b := models.Binaries{
+			Name:             uniqueTempName(t),
+			DownloadFileName: "tool.tar.gz",
+			DownloadURL:      srv.URL + "/tool.tar.gz",
+		}
+		_, err := downloadFile(b)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, hits.Load())
+	})
+
+	t.Run("server_errors_are_retried_once_per_attempt", func(t *testing.T) {
+		var hits atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			hits.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		t.Cleanup(srv.Close)
+
+		b := models.Binaries{
+			Name:             uniqueTempName(t),
+			DownloadFileName: "tool.tar.gz",
+			DownloadURL:      srv.URL + "/tool.tar.gz",
+		}
+		_, err := downloadFile(b)
+		require.Error(t, err)
+		assert.EqualValues(t, 3, hits.Load(), "the download loop retries, not the client transport as well")
+	})
+}
+
+func TestParseContentRange(t *testing.T) {
+	start, total, ok := parseContentRange("bytes 100-199/200")
+	require.True(t, ok)
+	assert.EqualValues(t, 100, start)
+	assert.EqualValues(t, 200, total)
+
+	start, total, ok = parseContentRange("bytes 5-9/*")
+	require.True(t, ok)
+	assert.EqualValues(t, 5, start)
+	assert.EqualValues(t, -1, total)
+
+	for _, header := range []string{"", "bytes */200", "items 0-1/2", "bytes 0-1"} {
+		_, _, ok := parseContentRange(header)
+		assert.False(t, ok, header)
+	}
+}
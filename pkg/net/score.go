@@ -0,0 +1,286 @@
+package net
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg/utils"
+)
+
+// archiveExts are the archive types that uncompressFile can extract
+var archiveExts = []string{".tar.gz", ".tgz", ".tar.xz", ".txz", ".tar.bz2", ".tbz2", ".tbz", ".tar", ".zip", ".gz", ".xz", ".bz2"}
+
+// checksumExts, signatureExts and packageExts are never installable on their own
+var checksumExts = []string{".sha256", ".sha256sum", ".sha512", ".sha512sum", ".sha1", ".md5", ".sum", ".checksum", ".checksums"}
+var signatureExts = []string{".sig", ".asc", ".pem", ".cert", ".crt", ".minisig", ".sigstore", ".intoto.jsonl"}
+var packageExts = []string{".deb", ".rpm", ".apk", ".msi", ".pkg", ".dmg", ".snap", ".flatpak"}
+
+// debugMarkers mark assets that only contain debug symbols
+var debugMarkers = []string{"debug", "-dbg", "_dbg", ".dbg", ".pdb", ".dsym", "symbols"}
+
+// Asset scores. An asset for the target OS and arch starts at scoreBase and
+// the adjustments below are applied on top of it.
+const (
+	scoreBase       = 100
+	scoreArchive    = 30
+	scoreRawBinary  = 20
+	scoreUnknownExt = -10
+	scoreUniversal  = -5
+	scoreArmVariant = 10
+	scoreLibcMatch  = 15
+	scoreLibcStatic = 10
+	scoreLibcOther  = -15
+	scoreDebug      = -60
+)
+
+// ExplainAssets resolves the release of b the same way CheckUpdates does and
+// returns the score of every asset, with the asset that would be downloaded
+// marked as picked. The returned binary has the resolved release recorded.
+func ExplainAssets(b models.Binaries, token string) (models.Binaries, []models.AssetScore, error) {
+	b, err := findProvider(b)
+	if err != nil {
+		return models.Binaries{}, nil, err
+	}
+	if b.Provider == Others {
+		return models.Binaries{}, nil, fmt.Errorf("%s uses the %s provider which has no release assets to explain", b.Name, Others)
+	}
+
+	rel, err := resolveRelease(b, token)
+	if err != nil {
+		return models.Binaries{}, nil, err
+	}
+
+	scores, err := scoreAssets(b, rel, TargetFor(b))
+	if err != nil {
+		return models.Binaries{}, nil, err
+	}
+
+	picked, err := selectAsset(b, rel)
+	if err != nil {
+		return models.Binaries{}, nil, err
+	}
+	picked.NewVersion = rel.TagName
+
+	best := bestAsset(scores)
+	for i := range scores {
+		if picked.DownloadURL == "" || rel.Assets[i].Name != picked.DownloadFileName {
+			continue
+		}
+		scores[i].Picked = true
+		if i != best {
+			scores[i].Reasons = append(scores[i].Reasons, "picked by the download or assetPattern config")
+		}
+	}
+	return picked, scores, nil
+}
+
+// TargetFor returns the platform assets of b are picked for. It is the host,
+// with its GOARM variant on 32-bit arm, unless Target overrides the OS or arch.
+func TargetFor(b models.Binaries) models.OSArch {
+	t := models.OSArch{OS: runtime.GOOS, Arch: runtime.GOARCH, Variant: utils.HostArmVariant()}
+	if b.Target.OS != "" {
+		t.OS = strings.ToLower(strings.TrimSpace(b.Target.OS))
+	}
+	if b.Target.Arch != "" {
+		arch, variant := utils.ParseArch(b.Target.Arch)
+		if arch != t.Arch || variant != "" {
+			t.Arch, t.Variant = arch, variant
+		}
+	}
+	t.Libc = preferredLibc(b, t)
+	return t
+}
+
+// isHostTarget reports whether b is installed for the host, so the
+// downloaded binaries can be executed
+func isHostTarget(b models.Binaries) bool {
+	return runsOn(TargetFor(b), runtime.GOOS, runtime.GOARCH, utils.HostArmVariant())
+}
+
+// runsOn reports whether binaries built for target run on a goos/goarch
+// system supporting the arm variant. A newer arm variant than the system's
+// doesn't run.
+func runsOn(target models.OSArch, goos, goarch, variant string) bool {
+	if target.OS != goos || target.Arch != goarch {
+		return false
+	}
+	return target.Variant == "" || variant == "" || target.Variant <= variant
+}
+
+// preferredLibc returns the C library to prefer for b on target: the
+// configured Libc, otherwise the one of the host when target is the host
+func preferredLibc(b models.Binaries, target models.OSArch) string {
+	if b.Libc != "" {
+		return utils.NormalizeLibc(b.Libc)
+	}
+	if target.OS != runtime.GOOS || target.Arch != runtime.GOARCH {
+		return ""
+	}
+	return utils.HostLibc()
+}
+
+// scoreAssets rates every asset of rel for target and returns the scores in
+// release order. The Libc of target is the preferred C library ("gnu", "musl"
+// or empty for no preference) and only affects Linux assets. Assets matching
+// the AssetExclude patterns of b are rejected.
+func scoreAssets(b models.Binaries, rel release, target models.OSArch) ([]models.AssetScore, error) {
+	scores := make([]models.AssetScore, 0, len(rel.Assets))
+	for _, asset := range rel.Assets {
+		excluded, err := isExcluded(b, asset)
+		if err != nil {
+			return nil, err
+		}
+		if excluded {
+			scores = append(scores, models.AssetScore{
+				Name:     asset.Name,
+				OSArch:   utils.FigureOutOSAndArch(asset.Name),
+				Rejected: true,
+				Reasons:  []string{"excluded by assetExclude"},
+			})
+			continue
+		}
+		scores = append(scores, scoreAsset(asset.Name, target))
+	}
+	return scores, nil
+}
+
+// scoreAsset rates a single asset name for target. Assets that can't be
+// installed on the target are rejected, the rest get a score where higher is
+// better, together with the reasons for it.
+func scoreAsset(name string, target models.OSArch) models.AssetScore {
+	s := models.AssetScore{Name: name, OSArch: utils.FigureOutOSAndArch(name)}
+	goos, goarch, libc := target.OS, target.Arch, target.Libc
+	lower := strings.ToLower(name)
+
+	reject := func(reason string) models.AssetScore {
+		s.Rejected = true
+		s.Score = 0
+		s.Reasons = append(s.Reasons, reason)
+		return s
+	}
+
+	switch {
+	case hasAnySuffix(lower, checksumExts) || strings.Contains(lower, "checksums") || strings.HasSuffix(lower, "sums.txt"):
+		return reject("checksum file")
+	case hasAnySuffix(lower, signatureExts):
+		return reject("signature file")
+	case strings.Contains(lower, "sbom") || strings.Contains(lower, ".spdx") || strings.Contains(lower, ".cdx."):
+		return reject("sbom file")
+	case hasAnySuffix(lower, packageExts):
+		return reject("system package")
+	}
+
+	if s.OSArch.OS != goos {
+		return reject(fmt.Sprintf("os %s does not match %s", s.OSArch.OS, goos))
+	}
+	s.Score = scoreBase
+
+	if s.OSArch.Arch != goarch {
+		// macOS universal binaries run on every arch
+		if goos != "darwin" || !strings.Contains(lower, "universal") {
+			return reject(fmt.Sprintf("arch %s does not match %s", s.OSArch.Arch, goarch))
+		}
+		s.Score += scoreUniversal
+		s.Reasons = append(s.Reasons, "universal binary")
+	} else {
+		s.Reasons = append(s.Reasons, fmt.Sprintf("matches %s/%s", goos, goarch))
+	}
+
+	if goarch == "arm" && s.OSArch.Variant != "" && target.Variant != "" {
+		switch {
+		case s.OSArch.Variant > target.Variant:
+			return reject(fmt.Sprintf("armv%s does not run on armv%s", s.OSArch.Variant, target.Variant))
+		case s.OSArch.Variant == target.Variant:
+			s.Score += scoreArmVariant
+			s.Reasons = append(s.Reasons, "matches armv"+target.Variant)
+		default:
+			// Older variants run too, but the closest one is preferred
+			diff := int(target.Variant[0] - s.OSArch.Variant[0])
+			s.Score += scoreArmVariant - 3*diff
+			s.Reasons = append(s.Reasons, fmt.Sprintf("armv%s runs on armv%s", s.OSArch.Variant, target.Variant))
+		}
+	}
+
+	switch ext := assetExt(lower); {
+	case slices.Contains(archiveExts, ext):
+		s.Score += scoreArchive
+		s.Reasons = append(s.Reasons, "extractable archive")
+	case ext == "" || (goos == "windows" && ext == ".exe"):
+		s.Score += scoreRawBinary
+		s.Reasons = append(s.Reasons, "raw binary")
+	default:
+		s.Score += scoreUnknownExt
+		s.Reasons = append(s.Reasons, fmt.Sprintf("unknown file type %s", ext))
+	}
+
+	if containsAny(lower, debugMarkers) {
+		s.Score += scoreDebug
+		s.Reasons = append(s.Reasons, "debug symbols")
+	}
+
+	if goos == "linux" {
+		switch assetLibc := s.OSArch.Libc; {
+		case assetLibc == utils.LibcStatic:
+			s.Score += scoreLibcStatic
+			s.Reasons = append(s.Reasons, "statically linked")
+		case assetLibc == "" || libc == "":
+		case assetLibc == libc:
+			s.Score += scoreLibcMatch
+			s.Reasons = append(s.Reasons, fmt.Sprintf("%s libc preferred", assetLibc))
+		default:
+			s.Score += scoreLibcOther
+			s.Reasons = append(s.Reasons, fmt.Sprintf("%s libc, %s preferred", assetLibc, libc))
+		}
+	}
+
+	return s
+}
+
+// bestAsset returns the index of the highest scoring asset that wasn't
+// rejected, or -1. On a tie the asset listed first in the release wins.
+func bestAsset(scores []models.AssetScore) int {
+	best := -1
+	for i, s := range scores {
+		if s.Rejected {
+			continue
+		}
+		if best == -1 || s.Score > scores[best].Score {
+			best = i
+		}
+	}
+	return best
+}
+
+// assetExt returns the extension of an asset name, keeping double extensions
+// such as ".tar.gz" together. Version numbers like "1.2.3" are not extensions.
+func assetExt(name string) string {
+	for _, ext := range archiveExts {
+		if strings.HasSuffix(name, ext) {
+			return ext
+		}
+	}
+	i := strings.LastIndex(name, ".")
+	if i == -1 {
+		return ""
+	}
+	ext := name[i:]
+	if len(ext) == 1 || strings.ContainsAny(ext[1:2], "0123456789") {
+		return ""
+	}
+	return ext
+}
+
+func hasAnySuffix(s string, suffixes []string) bool {
+	return slices.ContainsFunc(suffixes, func(suffix string) bool {
+		return strings.HasSuffix(s, suffix)
+	})
+}
+
+func containsAny(s string, subs []string) bool {
+	return slices.ContainsFunc(subs, func(sub string) bool {
+		return strings.Contains(s, sub)
+	})
+}
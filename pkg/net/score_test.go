@@ -0,0 +1,205 @@
+package net
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-github/v89/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg/utils"
+)
+
+func TestScoreAsset(t *testing.T) {
+	tests := []struct {
+		name     string
+		asset    string
+		goos     string
+		goarch   string
+		variant  string
+		libc     string
+		rejected bool
+		reason   string
+	}{
+		{name: "archive", asset: "tool_1.2.3_linux_amd64.tar.gz", goos: "linux", goarch: "amd64", reason: "extractable archive"},
+		{name: "raw_binary", asset: "tool-linux-amd64", goos: "linux", goarch: "amd64", reason: "raw binary"},
+		{name: "raw_binary_with_version", asset: "tool-1.2.3-linux-amd64", goos: "linux", goarch: "amd64", reason: "raw binary"},
+		{name: "windows_exe", asset: "tool-windows-amd64.exe", goos: "windows", goarch: "amd64", reason: "raw binary"},
+		{name: "checksum", asset: "tool_linux_amd64.tar.gz.sha256", goos: "linux", goarch: "amd64", rejected: true, reason: "checksum file"},
+		{name: "checksums_txt", asset: "tool_1.2.3_checksums.txt", goos: "linux", goarch: "amd64", rejected: true, reason: "checksum file"},
+		{name: "signature", asset: "tool_linux_amd64.tar.gz.sig", goos: "linux", goarch: "amd64", rejected: true, reason: "signature file"},
+		{name: "sbom", asset: "tool_linux_amd64.sbom.json", goos: "linux", goarch: "amd64", rejected: true, reason: "sbom file"},
+		{name: "package", asset: "tool_linux_amd64.deb", goos: "linux", goarch: "amd64", rejected: true, reason: "system package"},
+		{name: "wrong_os", asset: "tool_darwin_amd64.tar.gz", goos: "linux", goarch: "amd64", rejected: true, reason: "os darwin does not match linux"},
+		{name: "wrong_arch", asset: "tool_linux_arm64.tar.gz", goos: "linux", goarch: "amd64", rejected: true, reason: "arch arm64 does not match amd64"},
+		{name: "darwin_universal", asset: "tool_darwin_universal.tar.gz", goos: "darwin", goarch: "arm64", reason: "universal binary"},
+		{name: "debug", asset: "tool_linux_amd64-debug.tar.gz", goos: "linux", goarch: "amd64", reason: "debug symbols"},
+		{name: "libc_match", asset: "tool-x86_64-unknown-linux-musl.tar.gz", goos: "linux", goarch: "amd64", libc: "musl", reason: "musl libc preferred"},
+		{name: "libc_other", asset: "tool-x86_64-unknown-linux-gnu.tar.gz", goos: "linux", goarch: "amd64", libc: "musl", reason: "gnu libc, musl preferred"},
+		{name: "libc_static", asset: "tool-linux-amd64-static.tar.gz", goos: "linux", goarch: "amd64", libc: "musl", reason: "statically linked"},
+		{name: "arm_variant_match", asset: "tool_linux_armv7.tar.gz", goos: "linux", goarch: "arm", variant: "7", reason: "matches armv7"},
+		{name: "arm_older_variant", asset: "tool_linux_armv6.tar.gz", goos: "linux", goarch: "arm", variant: "7", reason: "armv6 runs on armv7"},
+		{name: "arm_newer_variant", asset: "tool_linux_armv7.tar.gz", goos: "linux", goarch: "arm", variant: "6", rejected: true, reason: "armv7 does not run on armv6"},
+		{name: "riscv64", asset: "tool-riscv64gc-unknown-linux-gnu.tar.gz", goos: "linux", goarch: "riscv64", reason: "matches linux/riscv64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scoreAsset(tt.asset, models.OSArch{OS: tt.goos, Arch: tt.goarch, Variant: tt.variant, Libc: tt.libc})
+			assert.Equal(t, tt.rejected, got.Rejected)
+			assert.Contains(t, got.Reasons, tt.reason)
+			if tt.rejected {
+				assert.Zero(t, got.Score)
+			} else {
+				assert.Positive(t, got.Score)
+			}
+		})
+	}
+}
+
+func TestScoreAssets_Best(t *testing.T) {
+	pick := func(t *testing.T, b models.Binaries, libc string, names ...string) string {
+		t.Helper()
+		rel := release{TagName: "v1.2.3"}
+		for _, name := range names {
+			rel.Assets = append(rel.Assets, releaseAsset{Name: name})
+		}
+		scores, err := scoreAssets(b, rel, models.OSArch{OS: "linux", Arch: "amd64", Libc: libc})
+		require.NoError(t, err)
+		require.Len(t, scores, len(names))
+		i := bestAsset(scores)
+		if i == -1 {
+			return ""
+		}
+		return scores[i].Name
+	}
+
+	t.Run("checksum_listed_first_is_skipped", func(t *testing.T) {
+		got := pick(t, models.Binaries{}, "", "tool_linux_amd64.tar.gz.sha256", "tool_linux_amd64.tar.gz")
+		assert.Equal(t, "tool_linux_amd64.tar.gz", got)
+	})
+
+	t.Run("archive_preferred_over_unknown_type", func(t *testing.T) {
+		got := pick(t, models.Binaries{}, "", "tool_linux_amd64.AppImage", "tool_linux_amd64.zip")
+		assert.Equal(t, "tool_linux_amd64.zip", got)
+	})
+
+	t.Run("debug_archive_loses", func(t *testing.T) {
+		got := pick(t, models.Binaries{}, "", "tool_linux_amd64-debug.tar.gz", "tool_linux_amd64")
+		assert.Equal(t, "tool_linux_amd64", got)
+	})
+
+	t.Run("libc_preference", func(t *testing.T) {
+		names := []string{"tool-x86_64-unknown-linux-gnu.tar.gz", "tool-x86_64-unknown-linux-musl.tar.gz"}
+		assert.Equal(t, names[1], pick(t, models.Binaries{}, "musl", names...))
+		assert.Equal(t, names[0], pick(t, models.Binaries{}, "gnu", names...))
+		assert.Equal(t, names[0], pick(t, models.Binaries{}, "", names...), "first listed wins without a preference")
+	})
+
+	t.Run("excluded_assets_are_rejected", func(t *testing.T) {
+		b := models.Binaries{AssetExclude: []string{"*-gnu.tar.gz"}}
+		got := pick(t, b, "", "tool-x86_64-unknown-linux-gnu.tar.gz", "tool-x86_64-unknown-linux-musl.tar.gz")
+		assert.Equal(t, "tool-x86_64-unknown-linux-musl.tar.gz", got)
+	})
+
+	t.Run("closest_arm_variant", func(t *testing.T) {
+		rel := release{TagName: "v1.2.3", Assets: []releaseAsset{{Name: "tool_linux_armv5.tar.gz"}, {Name: "tool_linux_armv6.tar.gz"}, {Name: "tool_linux_armv7.tar.gz"}}}
+		scores, err := scoreAssets(models.Binaries{}, rel, models.OSArch{OS: "linux", Arch: "arm", Variant: "6"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, bestAsset(scores))
+	})
+
+	t.Run("nothing_installable", func(t *testing.T) {
+		assert.Empty(t, pick(t, models.Binaries{}, "", "tool_darwin_arm64.tar.gz", "checksums.txt"))
+	})
+}
+
+func TestExplainAssets(t *testing.T) {
+	assetName := currentOSArchAssetName("tar.gz")
+	withGitHubServer(t, "v1.2.3", []*github.ReleaseAsset{
+		{Name: github.Ptr(assetName + ".sha256"), BrowserDownloadURL: github.Ptr("https://example.test/" + assetName + ".sha256")},
+		{Name: github.Ptr(assetName), BrowserDownloadURL: github.Ptr("https://example.test/" + assetName)},
+		{Name: github.Ptr("tool-plan9-mips.tar.gz"), BrowserDownloadURL: github.Ptr("https://example.test/tool-plan9-mips.tar.gz")},
+	})
+
+	t.Run("marks_the_picked_asset", func(t *testing.T) {
+		b := models.Binaries{Name: "tool", URL: "https://github.com/owner/repo"}
+		got, scores, err := ExplainAssets(b, "")
+		require.NoError(t, err)
+		assert.Equal(t, "v1.2.3", got.NewVersion)
+		assert.Equal(t, assetName, got.DownloadFileName)
+		require.Len(t, scores, 3)
+		assert.True(t, scores[0].Rejected)
+		assert.True(t, scores[1].Picked)
+		assert.False(t, scores[2].Picked)
+		assert.True(t, scores[2].Rejected)
+	})
+
+	t.Run("picked_by_pattern_is_noted", func(t *testing.T) {
+		b := models.Binaries{Name: "tool", URL: "https://github.com/owner/repo", AssetPattern: "*.sha256"}
+		_, scores, err := ExplainAssets(b, "")
+		require.NoError(t, err)
+		assert.True(t, scores[0].Picked)
+		assert.Contains(t, scores[0].Reasons, "picked by the download or assetPattern config")
+	})
+
+	t.Run("others_provider_is_an_error", func(t *testing.T) {
+		b := models.Binaries{Name: "tool", URL: "https://dl.example.com/tool", Provider: Others}
+		_, _, err := ExplainAssets(b, "")
+		require.Error(t, err)
+	})
+}
+
+func TestPreferredLibc(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("libc preference only applies to Linux assets")
+	}
+	gnu := fmt.Sprintf("tool-%s-unknown-linux-gnu.tar.gz", utils.ArchAlias(runtime.GOARCH))
+	musl := fmt.Sprintf("tool-%s-unknown-linux-musl.tar.gz", utils.ArchAlias(runtime.GOARCH))
+	withGitHubServer(t, "v1.2.3", []*github.ReleaseAsset{
+		{Name: github.Ptr(gnu), BrowserDownloadURL: github.Ptr("https://example.test/" + gnu)},
+		{Name: github.Ptr(musl), BrowserDownloadURL: github.Ptr("https://example.test/" + musl)},
+	})
+
+	for _, libc := range []string{"musl", "glibc"} {
+		t.Run("configured_"+libc, func(t *testing.T) {
+			b := models.Binaries{URL: "https://github.com/owner/repo", Provider: GitHub, Libc: libc}
+			got, err := checkForNewVersion(b)
+			require.NoError(t, err)
+			assert.Equal(t, utils.NormalizeLibc(libc), got.OsInfo.Libc)
+
+			rendered, err := renderTemplate(b, "tool-{{.ArchAlias}}-unknown-linux-{{.Libc}}.tar.gz", "v1.2.3")
+			require.NoError(t, err)
+			assert.Equal(t, got.DownloadFileName, rendered)
+		})
+	}
+
+	t.Run("host_by_default", func(t *testing.T) {
+		assert.Equal(t, utils.HostLibc(), TargetFor(models.Binaries{}).Libc)
+	})
+}
+
+func TestRunsOn(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  models.OSArch
+		variant string
+		want    bool
+	}{
+		{"same_platform", models.OSArch{OS: "linux", Arch: "arm"}, "7", true},
+		{"other_os", models.OSArch{OS: "darwin", Arch: "arm"}, "7", false},
+		{"other_arch", models.OSArch{OS: "linux", Arch: "arm64"}, "7", false},
+		{"older_variant", models.OSArch{OS: "linux", Arch: "arm", Variant: "6"}, "7", true},
+		{"same_variant", models.OSArch{OS: "linux", Arch: "arm", Variant: "7"}, "7", true},
+		{"newer_variant", models.OSArch{OS: "linux", Arch: "arm", Variant: "7"}, "6", false},
+		{"unknown_host_variant", models.OSArch{OS: "linux", Arch: "arm", Variant: "7"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, runsOn(tt.target, "linux", "arm", tt.variant))
+		})
+	}
+}
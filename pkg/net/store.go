@@ -0,0 +1,232 @@
+package net
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"github.com/sirupsen/logrus"
+
+	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg"
+	"github.com/akshaybabloo/binstall/pkg/fileio"
+)
+
+// versionDirName returns the name of the version store directory of b, the
+// version without a leading v
+func versionDirName(b models.Binaries) string {
+	v := tagVersion(b, b.NewVersion)
+	if _, err := version.NewVersion(v); err == nil {
+		v = strings.TrimPrefix(v, "v")
+	}
+	return strings.NewReplacer("/", "_", `\`, "_").Replace(v)
+}
+
+// moveVersioned installs the files of a versioned binary to
+// <store>/<name>/<version>/ and symlinks them into the install location.
+// b.InstalledFiles lists the symlinks and b.StoreDir the version directory.
+func moveVersioned(b *models.Binaries) error {
+	if err := expandInstallLocation(b); err != nil {
+		return err
+	}
+
+	root, err := fileio.StoreDir()
+	if err != nil {
+		return err
+	}
+	versionDir := filepath.Join(root, b.Name, versionDirName(*b))
+
+	// The files are staged next to the version directory, so a reinstall of
+	// the active version only replaces it once all files are in place
+	staging := versionDir + ".new"
+	if err := os.RemoveAll(staging); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", staging, err)
+	}
+
+	store := *b
+	store.InstallLocation = staging
+	store.BackupDir = ""
+	store.InstalledFiles = nil
+	store.InstalledDirs = nil
+	if err := moveFiles(&store); err != nil {
+		_ = os.RemoveAll(staging)
+		return err
+	}
+
+	if err := os.RemoveAll(versionDir); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", versionDir, err)
+	}
+	if err := os.Rename(staging, versionDir); err != nil {
+		return fmt.Errorf("failed to move %s to %s: %w", staging, versionDir, err)
+	}
+
+	if err := linkVersion(b, versionDir); err != nil {
+		return err
+	}
+	b.StoreDir = versionDir
+	return nil
+}
+
+// linkVersion symlinks every file of versionDir into b.InstallLocation,
+// keeping their relative paths. Replaced files are backed up.
+func linkVersion(b *models.Binaries, versionDir string) error {
+	err := filepath.Walk(versionDir, func(p string, info os.FileInfo, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(versionDir, p)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(b.InstallLocation, rel)
+
+		if err := mkdirAll(b, filepath.Dir(dst), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", dst, err)
+		}
+		if err := backupFile(b, dst); err != nil {
+			return err
+		}
+		if err := os.Remove(dst); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove existing file %s: %w", dst, err)
+		}
+
+		logrus.Debugf("Linking %s to %s", dst, p)
+		if err := os.Symlink(p, dst); err != nil {
+			return fmt.Errorf("failed to create symlink %s -> %s: %w", dst, p, err)
+		}
+		b.InstalledFiles = append(b.InstalledFiles, dst)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to link %s into %s: %w", versionDir, b.InstallLocation, err)
+	}
+	return nil
+}
+
+// UseVersion switches a versioned binary to another version in the store
+// and returns its new state entry. The symlinks of the current version are
+// replaced, they must not have been changed since they were installed.
+func UseVersion(installed models.InstalledBinary, v string) (models.InstalledBinary, error) {
+	if installed.Store == "" {
+		return models.InstalledBinary{}, fmt.Errorf("%s is not installed with versioned: true", installed.Name)
+	}
+
+	versionDir := filepath.Join(filepath.Dir(installed.Store), v)
+	if _, err := os.Stat(versionDir); err != nil {
+		// The store directories are named like versionDirName names them, e.g. 1.2.3 for v1.2.3
+		v = versionDirName(models.Binaries{NewVersion: v})
+		versionDir = filepath.Join(filepath.Dir(installed.Store), v)
+	}
+	if info, err := os.Stat(versionDir); err != nil || !info.IsDir() {
+		return models.InstalledBinary{}, fmt.Errorf("version %s of %s is not in the store, available: %s", v, installed.Name, strings.Join(storeVersions(installed), ", "))
+	}
+
+	for _, f := range installed.Files {
+		changed, err := fileModified(f)
+		if err != nil {
+			return models.InstalledBinary{}, err
+		}
+		if changed {
+			return models.InstalledBinary{}, fmt.Errorf("%w: %s", pkg.ErrNetFileModified, f.Path)
+		}
+		if err := os.Remove(f.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return models.InstalledBinary{}, fmt.Errorf("failed to remove %s: %w", f.Path, err)
+		}
+	}
+
+	b := models.Binaries{Name: installed.Name, InstallLocation: installed.InstallLocation}
+	if err := linkVersion(&b, versionDir); err != nil {
+		return models.InstalledBinary{}, err
+	}
+
+	files, err := installedFiles(b.InstalledFiles)
+	if err != nil {
+		return models.InstalledBinary{}, err
+	}
+
+	installed.Version = v
+	installed.Tag = ""
+	installed.Store = versionDir
+	installed.InstalledAt = time.Now().UTC()
+	installed.Files = files
+	installed.Dirs = append(installed.Dirs, b.InstalledDirs...)
+	return installed, nil
+}
+
+// storeVersions returns the versions of a versioned binary in the store,
+// most recently installed first
+func storeVersions(installed models.InstalledBinary) []string {
+	entries, err := os.ReadDir(filepath.Dir(installed.Store))
+	if err != nil {
+		return nil
+	}
+
+	type storeVersion struct {
+		name    string
+		modTime time.Time
+	}
+	var versions []storeVersion
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		versions = append(versions, storeVersion{name: e.Name(), modTime: info.ModTime()})
+	}
+	slices.SortFunc(versions, func(a, b storeVersion) int {
+		return cmp.Or(b.modTime.Compare(a.modTime), cmp.Compare(a.name, b.name))
+	})
+
+	var names []string
+	for _, v := range versions {
+		names = append(names, v.name)
+	}
+	return names
+}
+
+// PruneVersions removes all but the keep most recently installed versions of
+// a versioned binary from the store. The active version, and the version
+// rollback restores, are always kept. The removed versions are returned.
+func PruneVersions(installed models.InstalledBinary, keep int) ([]string, error) {
+	if installed.Store == "" {
+		return nil, nil
+	}
+
+	active := filepath.Base(installed.Store)
+	previous := ""
+	if installed.Previous != nil && installed.Previous.Store != "" {
+		previous = filepath.Base(installed.Previous.Store)
+	}
+	kept := 1
+	var removed []string
+	for _, v := range storeVersions(installed) {
+		if v == active || v == previous {
+			continue
+		}
+		if kept < keep {
+			kept++
+			continue
+		}
+
+		dir := filepath.Join(filepath.Dir(installed.Store), v)
+		logrus.Debugf("Removing %s", dir)
+		if err := os.RemoveAll(dir); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", dir, err)
+		}
+		removed = append(removed, v)
+	}
+	return removed, nil
+}
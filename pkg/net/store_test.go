@@ -0,0 +1,131 @@
+package net
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg"
+)
+
+// installVersion installs a versioned tool with the given version into
+// installDir and returns its state entry
+func installVersion(t *testing.T, installDir, v string) models.InstalledBinary {
+	t.Helper()
+	downloadDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(downloadDir, "share"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(downloadDir, "tool"), []byte("tool "+v), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(downloadDir, "share", "tool.txt"), []byte("docs "+v), 0o644))
+
+	b := models.Binaries{
+		Name:            "tool",
+		NewVersion:      "v" + v,
+		DownloadFolder:  downloadDir,
+		InstallLocation: installDir,
+		Versioned:       true,
+		Files:           []models.File{{FileName: "*", CopyIt: true}},
+	}
+	require.NoError(t, moveVersioned(&b))
+
+	installed, err := InstalledBinaryFor(b)
+	require.NoError(t, err)
+	return installed
+}
+
+func TestVersionedStore(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require elevated privileges on Windows")
+	}
+
+	storeRoot := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", storeRoot)
+	installDir := t.TempDir()
+	toolPath := filepath.Join(installDir, "tool")
+
+	first := installVersion(t, installDir, "1.0.0")
+	assert.Equal(t, filepath.Join(storeRoot, "binstall", "tool", "1.0.0"), first.Store)
+	assert.Equal(t, "1.0.0", first.Version)
+	target, err := os.Readlink(toolPath)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(first.Store, "tool"), target)
+
+	// Directory mtimes decide the install order of the versions
+	require.NoError(t, os.Chtimes(first.Store, time.Now().Add(-time.Hour), time.Now().Add(-time.Hour)))
+
+	second := installVersion(t, installDir, "2.0.0")
+	got, err := os.ReadFile(toolPath)
+	require.NoError(t, err)
+	assert.Equal(t, "tool 2.0.0", string(got))
+	assert.DirExists(t, first.Store, "older versions are kept side by side")
+
+	t.Run("use_switches_links", func(t *testing.T) {
+		switched, err := UseVersion(second, "1.0.0")
+		require.NoError(t, err)
+		assert.Equal(t, "1.0.0", switched.Version)
+		assert.Equal(t, first.Store, switched.Store)
+
+		got, err := os.ReadFile(filepath.Join(installDir, "share", "tool.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "docs 1.0.0", string(got))
+
+		second, err = UseVersion(switched, "2.0.0")
+		require.NoError(t, err)
+	})
+
+	t.Run("use_accepts_tag_prefix", func(t *testing.T) {
+		switched, err := UseVersion(second, "v1.0.0")
+		require.NoError(t, err)
+		assert.Equal(t, "1.0.0", switched.Version)
+		assert.Equal(t, first.Store, switched.Store)
+
+		second, err = UseVersion(switched, "v2.0.0")
+		require.NoError(t, err)
+	})
+
+	t.Run("use_unknown_version", func(t *testing.T) {
+		_, err := UseVersion(second, "3.0.0")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "2.0.0, 1.0.0")
+	})
+
+	t.Run("use_refuses_replaced_links", func(t *testing.T) {
+		require.NoError(t, os.Remove(toolPath))
+		require.NoError(t, os.WriteFile(toolPath, []byte("user"), 0o755))
+		t.Cleanup(func() {
+			require.NoError(t, os.Remove(toolPath))
+			require.NoError(t, os.Symlink(filepath.Join(second.Store, "tool"), toolPath))
+		})
+
+		_, err := UseVersion(second, "1.0.0")
+		require.ErrorIs(t, err, pkg.ErrNetFileModified)
+	})
+
+	t.Run("prune_keeps_rollback_version", func(t *testing.T) {
+		withPrevious := second
+		withPrevious.Previous = &first
+		removed, err := PruneVersions(withPrevious, 1)
+		require.NoError(t, err)
+		assert.Empty(t, removed)
+		assert.DirExists(t, first.Store, "rollback restores the previous version")
+	})
+
+	t.Run("prune_keeps_active_version", func(t *testing.T) {
+		removed, err := PruneVersions(second, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1.0.0"}, removed)
+		assert.NoDirExists(t, first.Store)
+		assert.DirExists(t, second.Store)
+	})
+
+	t.Run("uninstall_removes_store", func(t *testing.T) {
+		require.NoError(t, Uninstall(second, false))
+		assert.NoFileExists(t, toolPath)
+		assert.NoDirExists(t, filepath.Dir(second.Store))
+	})
+}
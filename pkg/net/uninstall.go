@@ -0,0 +1,118 @@
+package net
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg"
+	"github.com/akshaybabloo/binstall/pkg/utils"
+)
+
+// Uninstall removes the files and symlinks recorded for an installed binary,
+// then the directories its install created if they are empty. Files that
+// changed since they were installed fail with pkg.ErrNetFileModified before
+// anything is removed, unless force is set. Files that are already gone are
+// skipped. The backup kept for a rollback and the version store of a
+// versioned binary are removed as well, then the postUninstall hook runs.
+func Uninstall(installed models.InstalledBinary, force bool) error {
+	if !force {
+		var modified []string
+		for _, f := range installed.Files {
+			changed, err := fileModified(f)
+			if err != nil {
+				return err
+			}
+			if changed {
+				modified = append(modified, f.Path)
+			}
+		}
+		if len(modified) > 0 {
+			return fmt.Errorf("%w: %s, use --force to remove them anyway", pkg.ErrNetFileModified, strings.Join(modified, ", "))
+		}
+	}
+
+	for _, f := range installed.Files {
+		logrus.Debugf("Removing %s", f.Path)
+		if err := os.Remove(f.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", f.Path, err)
+		}
+	}
+
+	if err := removeEmptyDirs(installed.Dirs); err != nil {
+		return err
+	}
+
+	if installed.Backup != "" {
+		if err := os.RemoveAll(installed.Backup); err != nil {
+			return fmt.Errorf("failed to remove backup %s: %w", installed.Backup, err)
+		}
+	}
+
+	// Every version of a versioned binary is removed from the store
+	if installed.Store != "" {
+		versions := filepath.Dir(installed.Store)
+		if err := os.RemoveAll(versions); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", versions, err)
+		}
+	}
+
+	env := hookEnv{Name: installed.Name, Version: installed.Version, InstallDir: installed.InstallLocation}
+	return runHook("postUninstall", installed.Hooks.PostUninstall, installed.Shell, installed.Hooks, env)
+}
+
+// removeEmptyDirs removes the directories an install created, children
+// before their parents. Directories that aren't empty are kept.
+func removeEmptyDirs(dirs []string) error {
+	dirs = slices.Clone(dirs)
+	slices.Reverse(dirs)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil || len(entries) > 0 {
+			continue
+		}
+		logrus.Debugf("Removing empty directory %s", dir)
+		if err := os.Remove(dir); err != nil {
+			return fmt.Errorf("failed to remove directory %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// fileModified reports if the installed file f no longer matches what was
+// recorded at install time. A missing file is not modified.
+func fileModified(f models.InstalledFile) (bool, error) {
+	info, err := os.Lstat(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to stat %s: %w", f.Path, err)
+	}
+
+	if f.Symlink != "" {
+		if info.Mode()&os.ModeSymlink == 0 {
+			return true, nil
+		}
+		target, err := os.Readlink(f.Path)
+		if err != nil {
+			return false, fmt.Errorf("failed to read symlink %s: %w", f.Path, err)
+		}
+		return target != f.Symlink, nil
+	}
+
+	if !info.Mode().IsRegular() {
+		return true, nil
+	}
+	checksum, err := utils.CalculateSHA256(f.Path)
+	if err != nil {
+		return false, fmt.Errorf("failed to calculate sha256 for %s: %w", f.Path, err)
+	}
+	return checksum != f.SHA256, nil
+}
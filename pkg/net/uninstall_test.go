@@ -0,0 +1,112 @@
+package net
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akshaybabloo/binstall/models"
+	"github.com/akshaybabloo/binstall/pkg"
+)
+
+// installWildcard installs a tree with a nested directory and a symlink into
+// a fresh install location and returns its state entry
+func installWildcard(t *testing.T) models.InstalledBinary {
+	t.Helper()
+	downloadDir := t.TempDir()
+	installDir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(downloadDir, "share", "doc"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(downloadDir, "tool"), []byte("tool"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(downloadDir, "share", "doc", "README"), []byte("docs"), 0o644))
+	require.NoError(t, os.Symlink("tool", filepath.Join(downloadDir, "t")))
+
+	b := models.Binaries{
+		Name:            "tool",
+		DownloadFolder:  downloadDir,
+		InstallLocation: installDir,
+		Files:           []models.File{{FileName: "*", CopyIt: true}},
+	}
+	require.NoError(t, moveFiles(&b))
+
+	installed, err := InstalledBinaryFor(b)
+	require.NoError(t, err)
+	return installed
+}
+
+func TestUninstall(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require elevated privileges on Windows")
+	}
+
+	t.Run("removes_files_symlinks_and_created_dirs", func(t *testing.T) {
+		installed := installWildcard(t)
+		require.Len(t, installed.Files, 3)
+		assert.Equal(t, []string{
+			filepath.Join(installed.InstallLocation, "share"),
+			filepath.Join(installed.InstallLocation, "share", "doc"),
+		}, installed.Dirs)
+
+		require.NoError(t, Uninstall(installed, false))
+
+		entries, err := os.ReadDir(installed.InstallLocation)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+		assert.DirExists(t, installed.InstallLocation, "the existing install location is kept")
+	})
+
+	t.Run("refuses_modified_files", func(t *testing.T) {
+		installed := installWildcard(t)
+		toolPath := filepath.Join(installed.InstallLocation, "tool")
+		require.NoError(t, os.WriteFile(toolPath, []byte("changed"), 0o755))
+
+		err := Uninstall(installed, false)
+		require.ErrorIs(t, err, pkg.ErrNetFileModified)
+		assert.Contains(t, err.Error(), toolPath)
+		assert.FileExists(t, filepath.Join(installed.InstallLocation, "share", "doc", "README"), "nothing is removed")
+	})
+
+	t.Run("refuses_retargeted_symlinks", func(t *testing.T) {
+		installed := installWildcard(t)
+		linkPath := filepath.Join(installed.InstallLocation, "t")
+		require.NoError(t, os.Remove(linkPath))
+		require.NoError(t, os.Symlink("other", linkPath))
+
+		require.ErrorIs(t, Uninstall(installed, false), pkg.ErrNetFileModified)
+	})
+
+	t.Run("force_removes_modified_files", func(t *testing.T) {
+		installed := installWildcard(t)
+		require.NoError(t, os.WriteFile(filepath.Join(installed.InstallLocation, "tool"), []byte("changed"), 0o755))
+
+		require.NoError(t, Uninstall(installed, true))
+		assert.NoFileExists(t, filepath.Join(installed.InstallLocation, "tool"))
+	})
+
+	t.Run("keeps_non_empty_dirs_and_skips_missing_files", func(t *testing.T) {
+		installed := installWildcard(t)
+		require.NoError(t, os.Remove(filepath.Join(installed.InstallLocation, "tool")))
+		extra := filepath.Join(installed.InstallLocation, "share", "extra")
+		require.NoError(t, os.WriteFile(extra, []byte("user file"), 0o644))
+
+		require.NoError(t, Uninstall(installed, false))
+		assert.FileExists(t, extra)
+		assert.NoDirExists(t, filepath.Join(installed.InstallLocation, "share", "doc"))
+	})
+}
+
+func TestMkdirAll(t *testing.T) {
+	root := t.TempDir()
+	var b models.Binaries
+	require.NoError(t, mkdirAll(&b, filepath.Join(root, "a", "b"), 0o755))
+	require.NoError(t, mkdirAll(&b, filepath.Join(root, "a", "c"), 0o755))
+	assert.Equal(t, []string{
+		filepath.Join(root, "a"),
+		filepath.Join(root, "a", "b"),
+		filepath.Join(root, "a", "c"),
+	}, b.InstalledDirs)
+}
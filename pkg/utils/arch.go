@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"os"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// archSpelling maps a spelling of an architecture found in release file names
+// to the Go architecture
+type archSpelling struct {
+	spelling string
+	arch     string
+}
+
+// archSpellings are the known architecture spellings. A spelling only matches
+// as a whole word, and "x86_64" is listed before "x86" so the longer one wins.
+// 32-bit arm is detected separately by armRe since it carries a GOARM variant.
+var archSpellings = []archSpelling{
+	{"x86_64", "amd64"}, {"x86-64", "amd64"}, {"amd64", "amd64"}, {"x64", "amd64"},
+	{"aarch64", "arm64"}, {"arm64", "arm64"}, {"armv8", "arm64"},
+	{"i386", "386"}, {"i486", "386"}, {"i586", "386"}, {"i686", "386"}, {"386", "386"}, {"x86", "386"}, {"ia32", "386"},
+	{"riscv64gc", "riscv64"}, {"riscv64", "riscv64"},
+	{"ppc64le", "ppc64le"}, {"ppc64el", "ppc64le"}, {"powerpc64le", "ppc64le"},
+	{"ppc64", "ppc64"}, {"powerpc64", "ppc64"},
+	{"s390x", "s390x"},
+	{"loong64", "loong64"}, {"loongarch64", "loong64"},
+	{"mips64le", "mips64le"}, {"mips64el", "mips64le"}, {"mips64", "mips64"},
+	{"mipsle", "mipsle"}, {"mipsel", "mipsle"}, {"mips", "mips"},
+	{"wasm32", "wasm"}, {"wasm", "wasm"},
+}
+
+// archSpellingRes holds the whole word regular expression of each archSpellings entry
+var archSpellingRes = func() []*regexp.Regexp {
+	res := make([]*regexp.Regexp, len(archSpellings))
+	for i, s := range archSpellings {
+		res[i] = regexp.MustCompile(`(?:^|[^a-z0-9])` + regexp.QuoteMeta(s.spelling) + `(?:[^a-z0-9]|$)`)
+	}
+	return res
+}()
+
+// armRe matches 32-bit arm spellings: "arm", "armv5"-"armv7" with an optional
+// suffix such as "armv7l" or "armv6hf", "armhf" and "armel"
+var armRe = regexp.MustCompile(`(?:^|[^a-z0-9])arm(?:v([5-7])[a-z]*|hf|el)?(?:[^a-z0-9]|$)`)
+
+// figureOutArch returns the Go architecture and, for 32-bit arm, the GOARM
+// variant of a lower-cased release file name
+func figureOutArch(f string) (string, string) {
+	for i, re := range archSpellingRes {
+		if re.MatchString(f) {
+			return archSpellings[i].arch, ""
+		}
+	}
+
+	m := armRe.FindStringSubmatch(f)
+	if m == nil {
+		return "unknown", ""
+	}
+	switch {
+	case m[1] != "":
+		return "arm", m[1]
+	case strings.Contains(m[0], "armhf"):
+		// Debian's armhf port targets ARMv7
+		return "arm", "7"
+	case strings.Contains(m[0], "armel"):
+		return "arm", "5"
+	case strings.Contains(f, "eabihf"):
+		// Rust's arm-unknown-linux-gnueabihf target is ARMv6 with hardware floats
+		return "arm", "6"
+	default:
+		return "arm", ""
+	}
+}
+
+// ParseArch returns the Go architecture and GOARM variant of an architecture
+// name, e.g. "armv7l" -> ("arm", "7") or "x86_64" -> ("amd64", ""). Unknown
+// names are returned lower-cased.
+func ParseArch(arch string) (string, string) {
+	a := strings.ToLower(strings.TrimSpace(arch))
+	if goarch, variant := figureOutArch(a); goarch != "unknown" {
+		return goarch, variant
+	}
+	return a, ""
+}
+
+// HostArmVariant returns the GOARM variant ("5", "6" or "7") the running
+// system supports. It is empty when the system isn't 32-bit arm.
+var HostArmVariant = sync.OnceValue(func() string {
+	if runtime.GOARCH != "arm" {
+		return ""
+	}
+	if data, err := os.ReadFile("/proc/cpuinfo"); err == nil {
+		if v := armVariantFromCPUInfo(string(data)); v != "" {
+			return v
+		}
+	}
+	// Fall back to the variant binstall itself was built for
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "GOARM" && s.Value != "" {
+				return s.Value[:1]
+			}
+		}
+	}
+	return ""
+})
+
+var cpuArchRe = regexp.MustCompile(`(?m)^CPU architecture\s*:\s*(\d+)`)
+
+// armVariantFromCPUInfo reads the GOARM variant from the contents of
+// /proc/cpuinfo. ARMv8 CPUs running a 32-bit system run ARMv7 binaries.
+func armVariantFromCPUInfo(cpuinfo string) string {
+	m := cpuArchRe.FindStringSubmatch(cpuinfo)
+	if m == nil {
+		return ""
+	}
+	switch v := m[1]; {
+	case len(v) > 1 || v >= "7":
+		return "7"
+	case v < "5":
+		return ""
+	default:
+		return v
+	}
+}
@@ -5,11 +5,16 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -37,7 +42,82 @@ func ExpandGitHubURL(url string) models.GitHubInfo {
 	return github
 }
 
-// FigureOutOSAndArch figures out the OS and Arch of the system
+// SameHost reports whether both URLs point at the same host. Empty or
+// unparsable URLs never match.
+func SameHost(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil || ua.Host == "" {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil || ub.Host == "" {
+		return false
+	}
+	return strings.EqualFold(ua.Hostname(), ub.Hostname())
+}
+
+// IsGitLabURL reports whether the URL points at gitlab.com or a self-hosted
+// instance whose host name contains "gitlab"
+func IsGitLabURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(u.Hostname()), "gitlab")
+}
+
+// ExpandGitLabURL expands the GitLab URL and returns the models.GitLabInfo.
+// Nested groups are kept as part of the project path and anything after the
+// "/-/" separator (e.g. "/-/releases") is dropped.
+func ExpandGitLabURL(rawURL string) (models.GitLabInfo, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return models.GitLabInfo{}, fmt.Errorf("failed to parse GitLab URL %q: %w", rawURL, err)
+	}
+
+	project, _, _ := strings.Cut(u.Path, "/-/")
+	project = strings.TrimSuffix(strings.Trim(project, "/"), ".git")
+	if u.Host == "" || !strings.Contains(project, "/") {
+		return models.GitLabInfo{}, fmt.Errorf("invalid GitLab project URL: %s", rawURL)
+	}
+
+	return models.GitLabInfo{
+		BaseURL: u.Scheme + "://" + u.Host,
+		Project: project,
+	}, nil
+}
+
+// IsGiteaURL reports whether the URL points at Codeberg or a self-hosted
+// Gitea or Forgejo instance whose host name says so
+func IsGiteaURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return host == "codeberg.org" || strings.Contains(host, "gitea") || strings.Contains(host, "forgejo")
+}
+
+// ExpandGiteaURL expands a Gitea, Forgejo or Codeberg URL and returns the models.GiteaInfo
+func ExpandGiteaURL(rawURL string) (models.GiteaInfo, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return models.GiteaInfo{}, fmt.Errorf("failed to parse Gitea URL %q: %w", rawURL, err)
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if u.Host == "" || len(parts) < 2 || parts[0] == "" {
+		return models.GiteaInfo{}, fmt.Errorf("invalid Gitea repository URL: %s", rawURL)
+	}
+
+	return models.GiteaInfo{
+		BaseURL: u.Scheme + "://" + u.Host,
+		Owner:   parts[0],
+		Repo:    strings.TrimSuffix(parts[1], ".git"),
+	}, nil
+}
+
+// FigureOutOSAndArch figures out the OS, Arch and libc of a release file name
 func FigureOutOSAndArch(f string) models.OSArch {
 	var osArch models.OSArch
 
@@ -53,19 +133,32 @@ func FigureOutOSAndArch(f string) models.OSArch {
 		osArch.OS = "unknown"
 	}
 
-	if strings.Contains(f, "amd64") || strings.Contains(f, "x86_64") {
-		osArch.Arch = "amd64"
-	} else if strings.Contains(f, "386") {
-		osArch.Arch = "386"
-	} else if strings.Contains(f, "arm64") || strings.Contains(f, "aarch64") {
-		osArch.Arch = "arm64"
-	} else {
-		osArch.Arch = "unknown"
-	}
+	osArch.Arch, osArch.Variant = figureOutArch(f)
+
+	osArch.Libc = figureOutLibc(f)
 
 	return osArch
 }
 
+// MatchAssetPattern reports whether name matches pattern. Patterns wrapped in
+// slashes (e.g. "/^tool-.*-musl\.tar\.gz$/") are regular expressions, anything
+// else is a glob as understood by path.Match.
+func MatchAssetPattern(pattern, name string) (bool, error) {
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		r, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return false, fmt.Errorf("invalid asset pattern %q: %w", pattern, err)
+		}
+		return r.MatchString(name), nil
+	}
+
+	ok, err := path.Match(pattern, name)
+	if err != nil {
+		return false, fmt.Errorf("invalid asset pattern %q: %w", pattern, err)
+	}
+	return ok, nil
+}
+
 // CalculateSHA256 calculates the SHA256 checksum of a file
 func CalculateSHA256(filePath string) (string, error) {
 	file, err := os.Open(filePath)
@@ -172,6 +265,80 @@ func ExtractVersion(version, regex string) (string, error) {
 	return "", nil
 }
 
+// MatchVersion returns the first capture group of regex in s, or the whole
+// match if the regex has no groups. An error is returned if nothing matches.
+func MatchVersion(s, regex string) (string, error) {
+	r, err := regexp.Compile(regex)
+	if err != nil {
+		return "", err
+	}
+
+	matches := r.FindStringSubmatch(s)
+	switch {
+	case len(matches) > 1:
+		return strings.TrimSpace(matches[1]), nil
+	case len(matches) == 1:
+		return strings.TrimSpace(matches[0]), nil
+	default:
+		return "", fmt.Errorf("no match for %q", regex)
+	}
+}
+
+// jsonPathIndexRe matches the array indexes of a JSON path segment, e.g. "[0]"
+var jsonPathIndexRe = regexp.MustCompile(`\[(\d+)\]`)
+
+// LookupJSONPath returns the value at path in the JSON document data as a
+// string. The path is a dot separated list of keys with optional array
+// indexes and an optional "$" root, e.g. "$.releases[0].version" or "tag_name".
+func LookupJSONPath(data []byte, path string) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var current any
+	if err := decoder.Decode(&current); err != nil {
+		return "", fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	for _, segment := range strings.Split(path, ".") {
+		if segment == "" {
+			continue
+		}
+
+		key, _, _ := strings.Cut(segment, "[")
+		if key != "" {
+			object, ok := current.(map[string]any)
+			if !ok {
+				return "", fmt.Errorf("%q is not an object in path %q", key, path)
+			}
+			current, ok = object[key]
+			if !ok {
+				return "", fmt.Errorf("key %q not found in path %q", key, path)
+			}
+		}
+
+		for _, m := range jsonPathIndexRe.FindAllStringSubmatch(segment, -1) {
+			index, _ := strconv.Atoi(m[1])
+			list, ok := current.([]any)
+			if !ok || index >= len(list) {
+				return "", fmt.Errorf("index %d out of range in path %q", index, path)
+			}
+			current = list[index]
+		}
+	}
+
+	switch v := current.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case map[string]any, []any, nil:
+		return "", fmt.Errorf("path %q does not point to a string or number", path)
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
 // letterSuffixRe matches a numeric version with a single trailing lowercase
 // letter (e.g. "3.6a", "v1.1.1k"). Group 1 is the numeric part (with optional
 // "v"), group 2 is the suffix letter.
@@ -207,35 +374,146 @@ func NormalizeLetterSuffix(v string) string {
 	return fmt.Sprintf("%s.%d", m[1], pos)
 }
 
-// NormalizeArch normalizes architecture names to Go's runtime.GOARCH values
+// NormalizeArch normalizes architecture names to Go's runtime.GOARCH values,
+// e.g. "x86_64" -> "amd64", "armv7l" -> "arm" or "loongarch64" -> "loong64"
 func NormalizeArch(arch string) string {
-	switch strings.ToLower(arch) {
-	case "amd64", "x86_64":
-		return "amd64"
-	case "arm64", "aarch64":
-		return "arm64"
-	case "386", "i386", "i686":
-		return "386"
+	a := strings.ToLower(arch)
+	for _, s := range archSpellings {
+		if a == s.spelling {
+			return s.arch
+		}
+	}
+	if m := armRe.FindStringSubmatch(a); m != nil && m[0] == a {
+		return "arm"
+	}
+	return a
+}
+
+// ArchAlias returns the most common alternative spelling of a Go
+// architecture used in release file names, e.g. "amd64" -> "x86_64"
+func ArchAlias(arch string) string {
+	switch NormalizeArch(arch) {
+	case "amd64":
+		return "x86_64"
+	case "arm64":
+		return "aarch64"
+	case "386":
+		return "i386"
+	case "ppc64le":
+		return "powerpc64le"
+	case "ppc64":
+		return "powerpc64"
+	case "loong64":
+		return "loongarch64"
+	case "mipsle":
+		return "mipsel"
+	case "mips64le":
+		return "mips64el"
 	default:
-		return strings.ToLower(arch)
+		return arch
 	}
 }
 
 // TemplateData holds the data available to download fileName templates
 type TemplateData struct {
+	// Name is the name of the binary
+	Name string
+
+	// Version is the full release tag, e.g. "cli/v1.2.3"
 	Version string
+
+	// StrippedVersion is the tag with the configured tag prefix or pattern removed, e.g. "v1.2.3"
+	StrippedVersion string
+
+	// VersionNoV is StrippedVersion without a leading "v", e.g. "1.2.3"
+	VersionNoV string
+
+	// OS is the Go operating system name, e.g. "linux"
+	OS string
+
+	// Arch is the Go architecture name, e.g. "amd64"
+	Arch string
+
+	// ArchAlias is the common alternative spelling of Arch, e.g. "x86_64"
+	ArchAlias string
+
+	// Libc is the preferred C library on Linux, "gnu" or "musl", e.g. for "x86_64-unknown-linux-{{.Libc}}"
+	Libc string
 }
 
-// RenderDownloadTemplate renders a Go text/template string with the given version.
-// Returns the input unchanged if it contains no template syntax.
+// templateFuncs are the helper functions available to download templates.
+// Arguments are ordered so they work in pipelines, e.g. {{.Version | trimPrefix "v"}}
+var templateFuncs = template.FuncMap{
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"title":      title,
+}
+
+// title upper-cases the first letter of s, e.g. "darwin" -> "Darwin"
+func title(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	return strings.ToUpper(string(r[0])) + string(r[1:])
+}
+
+// RenderDownloadTemplate renders a Go text/template string with the given version
+// for the current OS/arch. Returns the input unchanged if it contains no template syntax.
 func RenderDownloadTemplate(tmpl string, version string) (string, error) {
-	t, err := template.New("download").Parse(tmpl)
+	return RenderTemplate(tmpl, TemplateData{
+		Version:         version,
+		StrippedVersion: version,
+		VersionNoV:      strings.TrimPrefix(version, "v"),
+		OS:              runtime.GOOS,
+		Arch:            runtime.GOARCH,
+		ArchAlias:       ArchAlias(runtime.GOARCH),
+		Libc:            HostLibc(),
+	})
+}
+
+// RenderTemplate renders a Go text/template string with the given data.
+// Returns the input unchanged if it contains no template syntax.
+func RenderTemplate(tmpl string, data TemplateData) (string, error) {
+	t, err := template.New("download").Funcs(templateFuncs).Parse(tmpl)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse download template %q: %w", tmpl, err)
 	}
 	var buf bytes.Buffer
-	if err := t.Execute(&buf, TemplateData{Version: version}); err != nil {
+	if err := t.Execute(&buf, data); err != nil {
 		return "", fmt.Errorf("failed to render download template %q: %w", tmpl, err)
 	}
 	return buf.String(), nil
 }
+
+// StripTag returns the version part of a release tag for monorepos that
+// publish several components, and whether the tag belongs to the component.
+// With a prefix (e.g. "cli/") the prefix is trimmed. With a pattern the
+// first capture group is the version, or the tag without the match if the
+// pattern has no groups. Without either the tag is returned unchanged.
+func StripTag(tag, prefix, pattern string) (string, bool, error) {
+	if prefix != "" {
+		if !strings.HasPrefix(tag, prefix) {
+			return "", false, nil
+		}
+		tag = strings.TrimPrefix(tag, prefix)
+	}
+
+	if pattern != "" {
+		r, err := regexp.Compile(pattern)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid tag pattern %q: %w", pattern, err)
+		}
+		m := r.FindStringSubmatchIndex(tag)
+		if m == nil {
+			return "", false, nil
+		}
+		if len(m) > 2 && m[2] >= 0 {
+			return tag[m[2]:m[3]], true, nil
+		}
+		return tag[:m[0]] + tag[m[1]:], true, nil
+	}
+
+	return tag, true, nil
+}
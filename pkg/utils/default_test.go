@@ -3,6 +3,7 @@ package utils
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"os"
 	"path/filepath"
@@ -25,6 +26,65 @@ func TestExpandGitHubURL(t *testing.T) {
 	assert.Equal(t, info, hubURL)
 }
 
+func TestExpandGitLabURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		want    models.GitLabInfo
+		wantErr bool
+	}{
+		{"gitlab.com", "https://gitlab.com/owner/repo", models.GitLabInfo{BaseURL: "https://gitlab.com", Project: "owner/repo"}, false},
+		{"nested groups", "https://gitlab.example.com/group/sub/repo", models.GitLabInfo{BaseURL: "https://gitlab.example.com", Project: "group/sub/repo"}, false},
+		{"releases page", "https://gitlab.com/owner/repo/-/releases", models.GitLabInfo{BaseURL: "https://gitlab.com", Project: "owner/repo"}, false},
+		{"git suffix", "https://gitlab.com/owner/repo.git", models.GitLabInfo{BaseURL: "https://gitlab.com", Project: "owner/repo"}, false},
+		{"missing repo", "https://gitlab.com/owner", models.GitLabInfo{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandGitLabURL(tt.url)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSameHost(t *testing.T) {
+	assert.True(t, SameHost("https://github.example.com/owner/repo", "https://GitHub.example.com/api/v3/"))
+	assert.False(t, SameHost("https://github.example.com/owner/repo", "https://github.com/"))
+	assert.False(t, SameHost("https://github.example.com/owner/repo", ""))
+}
+
+func TestIsGitLabURL(t *testing.T) {
+	assert.True(t, IsGitLabURL("https://gitlab.com/owner/repo"))
+	assert.True(t, IsGitLabURL("https://gitlab.example.com/owner/repo"))
+	assert.False(t, IsGitLabURL("https://github.com/owner/repo"))
+	assert.False(t, IsGitLabURL("https://example.com/gitlab/repo"))
+}
+
+func TestExpandGiteaURL(t *testing.T) {
+	got, err := ExpandGiteaURL("https://codeberg.org/forgejo/forgejo.git")
+	require.NoError(t, err)
+	assert.Equal(t, models.GiteaInfo{BaseURL: "https://codeberg.org", Owner: "forgejo", Repo: "forgejo"}, got)
+
+	got, err = ExpandGiteaURL("https://git.example.com/owner/repo/releases")
+	require.NoError(t, err)
+	assert.Equal(t, models.GiteaInfo{BaseURL: "https://git.example.com", Owner: "owner", Repo: "repo"}, got)
+
+	_, err = ExpandGiteaURL("https://codeberg.org/owner")
+	assert.Error(t, err)
+}
+
+func TestIsGiteaURL(t *testing.T) {
+	assert.True(t, IsGiteaURL("https://codeberg.org/owner/repo"))
+	assert.True(t, IsGiteaURL("https://gitea.example.com/owner/repo"))
+	assert.True(t, IsGiteaURL("https://forgejo.example.com/owner/repo"))
+	assert.False(t, IsGiteaURL("https://github.com/owner/repo"))
+}
+
 func TestFigureOutOSAndArch(t *testing.T) {
 	type args struct {
 		f string
@@ -50,6 +110,178 @@ func TestFigureOutOSAndArch(t *testing.T) {
 			OS:   "windows",
 			Arch: "amd64",
 		}},
+		{name: "linux gnu", args: args{f: "tool-x86_64-unknown-linux-gnu.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "amd64",
+			Libc: "gnu",
+		}},
+		{name: "linux musl", args: args{f: "tool-aarch64-unknown-linux-musl.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "arm64",
+			Libc: "musl",
+		}},
+		{name: "linux static", args: args{f: "tool_linux_amd64_static.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "amd64",
+			Libc: "static",
+		}},
+		{name: "linux x86_64", args: args{f: "tool_Linux_x86_64.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "amd64",
+		}},
+		{name: "linux x86-64", args: args{f: "tool-linux-x86-64.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "amd64",
+		}},
+		{name: "windows x64", args: args{f: "tool-windows-x64.zip"}, want: models.OSArch{
+			OS:   "windows",
+			Arch: "amd64",
+		}},
+		{name: "linux aarch64", args: args{f: "tool-linux-aarch64.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "arm64",
+		}},
+		{name: "linux armv8", args: args{f: "tool-linux-armv8.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "arm64",
+		}},
+		{name: "linux i386", args: args{f: "tool-linux-i386.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "386",
+		}},
+		{name: "linux i686", args: args{f: "tool-i686-unknown-linux-musl.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "386",
+			Libc: "musl",
+		}},
+		{name: "linux 386", args: args{f: "tool_linux_386.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "386",
+		}},
+		{name: "linux x86", args: args{f: "tool-linux-x86.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "386",
+		}},
+		{name: "linux armv7l", args: args{f: "tool-linux-armv7l.tar.gz"}, want: models.OSArch{
+			OS:      "linux",
+			Arch:    "arm",
+			Variant: "7",
+		}},
+		{name: "linux armv7 rust", args: args{f: "tool-armv7-unknown-linux-gnueabihf.tar.gz"}, want: models.OSArch{
+			OS:      "linux",
+			Arch:    "arm",
+			Variant: "7",
+			Libc:    "gnu",
+		}},
+		{name: "linux armv6", args: args{f: "tool_linux_armv6.tar.gz"}, want: models.OSArch{
+			OS:      "linux",
+			Arch:    "arm",
+			Variant: "6",
+		}},
+		{name: "linux armv6hf", args: args{f: "tool-linux-armv6hf.tar.gz"}, want: models.OSArch{
+			OS:      "linux",
+			Arch:    "arm",
+			Variant: "6",
+		}},
+		{name: "linux armv5", args: args{f: "tool_linux_armv5.tar.gz"}, want: models.OSArch{
+			OS:      "linux",
+			Arch:    "arm",
+			Variant: "5",
+		}},
+		{name: "linux armhf", args: args{f: "tool_linux_armhf.deb"}, want: models.OSArch{
+			OS:      "linux",
+			Arch:    "arm",
+			Variant: "7",
+		}},
+		{name: "linux armel", args: args{f: "tool_linux_armel.tar.gz"}, want: models.OSArch{
+			OS:      "linux",
+			Arch:    "arm",
+			Variant: "5",
+		}},
+		{name: "linux arm gnueabihf", args: args{f: "tool-arm-unknown-linux-gnueabihf.tar.gz"}, want: models.OSArch{
+			OS:      "linux",
+			Arch:    "arm",
+			Variant: "6",
+			Libc:    "gnu",
+		}},
+		{name: "linux arm", args: args{f: "tool_linux_arm.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "arm",
+		}},
+		{name: "linux riscv64", args: args{f: "tool_linux_riscv64.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "riscv64",
+		}},
+		{name: "linux riscv64gc", args: args{f: "tool-riscv64gc-unknown-linux-gnu.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "riscv64",
+			Libc: "gnu",
+		}},
+		{name: "linux ppc64le", args: args{f: "tool_linux_ppc64le.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "ppc64le",
+		}},
+		{name: "linux ppc64el", args: args{f: "tool_linux_ppc64el.deb"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "ppc64le",
+		}},
+		{name: "linux powerpc64le", args: args{f: "tool-powerpc64le-unknown-linux-gnu.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "ppc64le",
+			Libc: "gnu",
+		}},
+		{name: "linux ppc64", args: args{f: "tool_linux_ppc64.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "ppc64",
+		}},
+		{name: "linux powerpc64", args: args{f: "tool-powerpc64-unknown-linux-gnu.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "ppc64",
+			Libc: "gnu",
+		}},
+		{name: "linux s390x", args: args{f: "tool_linux_s390x.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "s390x",
+		}},
+		{name: "linux loong64", args: args{f: "tool_linux_loong64.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "loong64",
+		}},
+		{name: "linux loongarch64", args: args{f: "tool-loongarch64-unknown-linux-gnu.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "loong64",
+			Libc: "gnu",
+		}},
+		{name: "linux mips64le", args: args{f: "tool_linux_mips64le.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "mips64le",
+		}},
+		{name: "linux mips64el", args: args{f: "tool-mips64el-unknown-linux-gnuabi64.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "mips64le",
+			Libc: "gnu",
+		}},
+		{name: "linux mips64", args: args{f: "tool_linux_mips64.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "mips64",
+		}},
+		{name: "linux mipsle", args: args{f: "tool_linux_mipsle.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "mipsle",
+		}},
+		{name: "linux mipsel", args: args{f: "tool-mipsel-unknown-linux-musl.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "mipsle",
+			Libc: "musl",
+		}},
+		{name: "linux mips", args: args{f: "tool_linux_mips.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "mips",
+		}},
+		{name: "arm inside a word", args: args{f: "charm_linux_unknown.tar.gz"}, want: models.OSArch{
+			OS:   "linux",
+			Arch: "unknown",
+		}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -58,6 +290,35 @@ func TestFigureOutOSAndArch(t *testing.T) {
 	}
 }
 
+func TestMatchAssetPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		asset   string
+		want    bool
+		wantErr bool
+	}{
+		{"glob_match", "tool_*_linux_amd64.tar.gz", "tool_1.2.3_linux_amd64.tar.gz", true, false},
+		{"glob_no_match", "tool_*_linux_amd64.tar.gz", "tool_1.2.3_linux_arm64.tar.gz", false, false},
+		{"glob_character_class", "tool-[lm]*", "tool-musl", true, false},
+		{"regex_match", `/^tool-.*-musl\.tar\.gz$/`, "tool-linux-amd64-musl.tar.gz", true, false},
+		{"regex_no_match", `/^tool-.*-musl\.tar\.gz$/`, "tool-linux-amd64-gnu.tar.gz", false, false},
+		{"invalid_regex", "/[/", "tool", false, true},
+		{"invalid_glob", "[", "tool", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MatchAssetPattern(tt.pattern, tt.asset)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestCalculateSHA256(t *testing.T) {
 	// Create a temporary directory for test files
 	tempDir, err := os.MkdirTemp("", "sha256test")
@@ -128,6 +389,39 @@ func TestParseYaml(t *testing.T) {
 	}
 }
 
+func TestParseYaml_Provider(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want models.Provider
+	}{
+		{"name", "provider: gitea", "gitea"},
+		{"legacy_github", "provider: 1", "github"},
+		{"legacy_others", "provider: 2", "others"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseYaml([]byte(tt.yaml))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.Provider)
+		})
+	}
+
+	for _, n := range []string{"3", "7"} {
+		t.Run("unknown_number_"+n, func(t *testing.T) {
+			_, err := ParseYaml([]byte("provider: " + n))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "use github, gitlab, gitea or others")
+		})
+	}
+
+	t.Run("legacy_json", func(t *testing.T) {
+		var b models.Binaries
+		require.NoError(t, json.Unmarshal([]byte(`{"provider": 2}`), &b))
+		assert.Equal(t, models.Provider("others"), b.Provider)
+	})
+}
+
 func TestFileNameWithoutExtension(t *testing.T) {
 	type args struct {
 		fileName string
@@ -244,6 +538,65 @@ func FuzzExtractVersion(f *testing.F) {
 	})
 }
 
+func TestMatchVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		regex   string
+		want    string
+		wantErr bool
+	}{
+		{name: "whole_match", input: "version 1.2.3", regex: `\d+\.\d+\.\d+`, want: "1.2.3"},
+		{name: "capture_group", input: "tool v1.2.3 (linux)", regex: `v(\d+\.\d+\.\d+)`, want: "1.2.3"},
+		{name: "no_match", input: "nothing", regex: `\d+`, wantErr: true},
+		{name: "invalid_regex", input: "1", regex: "[", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MatchVersion(tt.input, tt.regex)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLookupJSONPath(t *testing.T) {
+	doc := []byte(`{"tag_name":"v1.0.0","build":42,"data":{"releases":[{"version":"2.0.0"},{"version":"1.9.0"}]},"matrix":[["a","b"]]}`)
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "top_level_key", path: "tag_name", want: "v1.0.0"},
+		{name: "dollar_root", path: "$.tag_name", want: "v1.0.0"},
+		{name: "number", path: "build", want: "42"},
+		{name: "nested_index", path: "$.data.releases[1].version", want: "1.9.0"},
+		{name: "nested_arrays", path: "matrix[0][1]", want: "b"},
+		{name: "missing_key", path: "data.missing", wantErr: true},
+		{name: "index_out_of_range", path: "data.releases[5].version", wantErr: true},
+		{name: "points_to_object", path: "data", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LookupJSONPath(doc, tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := LookupJSONPath([]byte("not json"), "a")
+	assert.Error(t, err)
+}
+
 func TestNormalizeLetterSuffix(t *testing.T) {
 	tests := []struct {
 		input string
@@ -295,6 +648,26 @@ func TestNormalizeArch(t *testing.T) {
 		{"AARCH64", "arm64"},
 		{"X86_64", "amd64"},
 		{"riscv64", "riscv64"},
+		{"riscv64gc", "riscv64"},
+		{"x64", "amd64"},
+		{"x86", "386"},
+		{"arm", "arm"},
+		{"armv6", "arm"},
+		{"armv7l", "arm"},
+		{"armhf", "arm"},
+		{"armel", "arm"},
+		{"armv8", "arm64"},
+		{"ppc64le", "ppc64le"},
+		{"ppc64el", "ppc64le"},
+		{"powerpc64le", "ppc64le"},
+		{"ppc64", "ppc64"},
+		{"s390x", "s390x"},
+		{"loong64", "loong64"},
+		{"LoongArch64", "loong64"},
+		{"mips64el", "mips64le"},
+		{"mipsel", "mipsle"},
+		{"wasm32", "wasm"},
+		{"sparc64", "sparc64"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
@@ -349,6 +722,103 @@ func TestRenderDownloadTemplate(t *testing.T) {
 	}
 }
 
+func TestRenderTemplate_StrippedVersion(t *testing.T) {
+	got, err := RenderTemplate("tool-{{.StrippedVersion}}.tar.gz ({{.Version}})", TemplateData{Version: "cli/v1.2.3", StrippedVersion: "v1.2.3"})
+	require.NoError(t, err)
+	assert.Equal(t, "tool-v1.2.3.tar.gz (cli/v1.2.3)", got)
+}
+
+func TestRenderTemplate(t *testing.T) {
+	data := TemplateData{
+		Name:            "tool",
+		Version:         "v1.2.3",
+		StrippedVersion: "v1.2.3",
+		VersionNoV:      "1.2.3",
+		OS:              "darwin",
+		Arch:            "arm64",
+		ArchAlias:       "aarch64",
+	}
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{"fields", "{{.Name}}_{{.VersionNoV}}_{{.OS}}_{{.ArchAlias}}.tar.gz", "tool_1.2.3_darwin_aarch64.tar.gz"},
+		{"trimPrefix", `{{.Version | trimPrefix "v"}}`, "1.2.3"},
+		{"trimSuffix", `{{trimSuffix ".3" .VersionNoV}}`, "1.2"},
+		{"replace", `{{replace "." "_" .VersionNoV}}`, "1_2_3"},
+		{"title", "{{.Name}}-{{title .OS}}-{{.Arch}}", "tool-Darwin-arm64"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderTemplate(tt.tmpl, data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestArchAlias(t *testing.T) {
+	assert.Equal(t, "x86_64", ArchAlias("amd64"))
+	assert.Equal(t, "aarch64", ArchAlias("arm64"))
+	assert.Equal(t, "i386", ArchAlias("386"))
+	assert.Equal(t, "riscv64", ArchAlias("riscv64"))
+	assert.Equal(t, "powerpc64le", ArchAlias("ppc64le"))
+	assert.Equal(t, "loongarch64", ArchAlias("loong64"))
+	assert.Equal(t, "s390x", ArchAlias("s390x"))
+}
+
+func TestArmVariantFromCPUInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		cpuinfo string
+		want    string
+	}{
+		{"armv6", "processor\t: 0\nmodel name\t: ARMv6-compatible processor rev 7 (v6l)\nCPU architecture: 6\n", "6"},
+		{"armv7", "processor\t: 0\nCPU architecture: 7\n", "7"},
+		{"armv8_32bit", "processor\t: 0\nCPU architecture: 8\n", "7"},
+		{"missing", "processor\t: 0\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, armVariantFromCPUInfo(tt.cpuinfo))
+		})
+	}
+}
+
+func TestStripTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		prefix  string
+		pattern string
+		want    string
+		match   bool
+		wantErr bool
+	}{
+		{name: "no_filter", tag: "v1.2.3", want: "v1.2.3", match: true},
+		{name: "prefix_match", tag: "cli/v1.2.3", prefix: "cli/", want: "v1.2.3", match: true},
+		{name: "prefix_other_component", tag: "sdk/v4.0.0", prefix: "cli/", match: false},
+		{name: "pattern_capture_group", tag: "tool-cli-1.2.3", pattern: `^tool-cli-(\d+\.\d+\.\d+)$`, want: "1.2.3", match: true},
+		{name: "pattern_without_group_removes_match", tag: "cli/v1.2.3", pattern: `^cli/`, want: "v1.2.3", match: true},
+		{name: "pattern_no_match", tag: "sdk/v4.0.0", pattern: `^cli/(.*)$`, match: false},
+		{name: "prefix_and_pattern", tag: "cli/release-1.0", prefix: "cli/", pattern: `release-(.*)`, want: "1.0", match: true},
+		{name: "invalid_pattern", tag: "v1", pattern: "[", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := StripTag(tt.tag, tt.prefix, tt.pattern)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.match, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestContains(t *testing.T) {
 	tests := []struct {
 		name string
@@ -423,3 +893,25 @@ files:
 	assert.Contains(t, b.Download["linux"], "aarch64")
 	assert.Equal(t, "bat-{{.Version}}-aarch64-unknown-linux-gnu.tar.gz", b.Download["linux"]["aarch64"].FileName)
 }
+
+func TestParseArch(t *testing.T) {
+	tests := []struct {
+		input   string
+		arch    string
+		variant string
+	}{
+		{"amd64", "amd64", ""},
+		{"x86_64", "amd64", ""},
+		{"armv7", "arm", "7"},
+		{"ARMv6", "arm", "6"},
+		{"arm", "arm", ""},
+		{"sparc64", "sparc64", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			arch, variant := ParseArch(tt.input)
+			assert.Equal(t, tt.arch, arch)
+			assert.Equal(t, tt.variant, variant)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"debug/elf"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// C libraries a Linux binary can be built against
+const (
+	LibcGNU    = "gnu"
+	LibcMusl   = "musl"
+	LibcStatic = "static"
+)
+
+// libcProbes are the system binaries whose ELF interpreter tells the host libc
+var libcProbes = []string{"bin/sh", "usr/bin/env", "bin/ls"}
+
+// HostLibc returns the C library of the running system, "gnu" or "musl".
+// It is empty on other operating systems or when it can't be detected.
+var HostLibc = sync.OnceValue(func() string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	return detectLibc("/")
+})
+
+// detectLibc detects the C library of the Linux system rooted at root. The
+// ELF interpreter of a system binary is checked first, since glibc systems can
+// have the musl loader installed too, then the presence of the musl loader.
+func detectLibc(root string) string {
+	for _, probe := range libcProbes {
+		interp := elfInterpreter(filepath.Join(root, probe))
+		switch {
+		case interp == "":
+			continue
+		case strings.Contains(interp, "musl"):
+			return LibcMusl
+		case strings.Contains(interp, "ld-linux"), strings.Contains(interp, "ld64.so"):
+			return LibcGNU
+		}
+	}
+
+	if loaders, _ := filepath.Glob(filepath.Join(root, "lib", "ld-musl-*.so.1")); len(loaders) > 0 {
+		return LibcMusl
+	}
+	if loaders, _ := filepath.Glob(filepath.Join(root, "lib*", "ld-linux*.so.*")); len(loaders) > 0 {
+		return LibcGNU
+	}
+	return ""
+}
+
+// elfInterpreter returns the program interpreter of the ELF file at p, or an
+// empty string if p isn't a dynamically linked ELF file
+func elfInterpreter(p string) string {
+	f, err := elf.Open(p)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	for _, prog := range f.Progs {
+		if prog.Type != elf.PT_INTERP {
+			continue
+		}
+		data := make([]byte, prog.Filesz)
+		if _, err := prog.ReadAt(data, 0); err != nil {
+			return ""
+		}
+		return strings.TrimRight(string(data), "\x00")
+	}
+	return ""
+}
+
+// NormalizeLibc lower-cases and trims a configured libc name and maps its
+// alias "glibc" to LibcGNU. Other names, e.g. "musl" or "static", are
+// returned as they are.
+func NormalizeLibc(libc string) string {
+	switch l := strings.ToLower(strings.TrimSpace(libc)); l {
+	case "gnu", "glibc":
+		return LibcGNU
+	default:
+		return l
+	}
+}
+
+// figureOutLibc classifies a lower-cased asset name as built for LibcMusl,
+// LibcStatic or LibcGNU. Returns an empty string when the name doesn't say.
+func figureOutLibc(f string) string {
+	switch {
+	case strings.Contains(f, "musl"):
+		return LibcMusl
+	case hasNameToken(f, "static"):
+		return LibcStatic
+	case hasNameToken(f, "gnu", "glibc", "gnueabi", "gnueabihf", "gnuabi64", "gnuabin32", "gnux32", "gnuspe", "gnullvm"):
+		return LibcGNU
+	default:
+		return ""
+	}
+}
+
+// hasNameToken reports whether one of tokens is one of the parts of the asset
+// name f separated by "-", "_" or ".", so "static" matches tool_static.tar.gz
+// but not staticcheck.tar.gz
+func hasNameToken(f string, tokens ...string) bool {
+	parts := strings.FieldsFunc(f, func(r rune) bool { return r == '-' || r == '_' || r == '.' })
+	return slices.ContainsFunc(parts, func(part string) bool {
+		return slices.Contains(tokens, part)
+	})
+}
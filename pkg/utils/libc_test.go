@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectLibc(t *testing.T) {
+	touch := func(t *testing.T, root, name string) {
+		t.Helper()
+		p := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte("not an elf file"), 0o755))
+	}
+
+	t.Run("musl_loader", func(t *testing.T) {
+		root := t.TempDir()
+		touch(t, root, "lib/ld-musl-x86_64.so.1")
+		touch(t, root, "bin/sh")
+		assert.Equal(t, LibcMusl, detectLibc(root))
+	})
+
+	t.Run("glibc_loader", func(t *testing.T) {
+		root := t.TempDir()
+		touch(t, root, "lib64/ld-linux-x86-64.so.2")
+		assert.Equal(t, LibcGNU, detectLibc(root))
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		assert.Empty(t, detectLibc(t.TempDir()))
+	})
+
+	t.Run("elf_interpreter_of_host_shell", func(t *testing.T) {
+		if runtime.GOOS != "linux" {
+			t.Skip("ELF interpreters are only checked on Linux")
+		}
+		interp := elfInterpreter("/bin/sh")
+		if interp == "" {
+			t.Skip("/bin/sh is not dynamically linked")
+		}
+
+		// A copy of the host shell without any loaders next to it is detected from its interpreter alone
+		data, err := os.ReadFile("/bin/sh")
+		require.NoError(t, err)
+		root := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(root, "bin"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "bin", "sh"), data, 0o755))
+
+		got := detectLibc(root)
+		assert.Contains(t, []string{LibcGNU, LibcMusl}, got, "interpreter %s", interp)
+	})
+}
+
+func TestNormalizeLibc(t *testing.T) {
+	assert.Equal(t, LibcGNU, NormalizeLibc("glibc"))
+	assert.Equal(t, LibcGNU, NormalizeLibc(" GNU "))
+	assert.Equal(t, LibcMusl, NormalizeLibc("musl"))
+}
+
+func TestFigureOutLibc(t *testing.T) {
+	tests := []struct {
+		name  string
+		asset string
+		want  string
+	}{
+		{"static_suffix", "tool_linux_amd64_static.tar.gz", LibcStatic},
+		{"static_part", "tool-static-linux-amd64", LibcStatic},
+		{"static_in_a_word", "staticcheck_linux_amd64.tar.gz", ""},
+		{"musl", "tool-x86_64-unknown-linux-musl.tar.gz", LibcMusl},
+		{"gnu", "tool-x86_64-unknown-linux-gnu.tar.gz", LibcGNU},
+		{"glibc", "tool_linux_amd64_glibc.tar.gz", LibcGNU},
+		{"gnueabi", "tool-arm-unknown-linux-gnueabi.tar.gz", LibcGNU},
+		{"gnueabihf", "tool-armv7-unknown-linux-gnueabihf.tar.gz", LibcGNU},
+		{"gnu_in_a_word", "gnuplot_linux_amd64.tar.gz", ""},
+		{"gnupg", "gnupg-2.4.5-linux-x86_64.tar.gz", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, figureOutLibc(tt.asset))
+		})
+	}
+}